@@ -28,6 +28,8 @@ var DefaultConfig = map[string]interface{}{
 	"http.enableSSL":        false,
 	"http.rateLimitEnabled": true,
 	"http.requestsPerMin":   100,
+	"http.proxyEnabled":     false,
+	"http.trustedProxies":   []string{"127.0.0.1", "::1"},
 
 	// Auth defaults
 	"auth.enableLocal":     true,
@@ -35,4 +37,19 @@ var DefaultConfig = map[string]interface{}{
 	"auth.enable2FA":       false,
 	"auth.tokenExpiration": 24,
 	"auth.allowedOrigins":  []string{"http://localhost:3000"},
+
+	// Analytics defaults
+	"analytics.ipHashSalt":     "",
+	"analytics.topReferrers":   10,
+	"analytics.rollupInterval": 5,
+
+	// Redirect defaults
+	"redirect.defaultStatus":       302,
+	"redirect.passwordMaxAttempts": 5,
+	"redirect.passwordLockout":     15,
+	"redirect.fallbackUrl":         "",
+	"redirect.fallbackPage":        "",
+
+	// GeoIP defaults
+	"geoip.databasePath": "",
 }
@@ -0,0 +1,21 @@
+// constants/reserved.go
+package constants
+
+// ReservedShortCodes lists path segments served by Portus itself at the root
+// of the host. Short links are resolved at /{code}, so none of these may be
+// used as a custom code or be produced by the code generator.
+var ReservedShortCodes = []string{
+	"api",
+	"swagger",
+	"health",
+	"docs",
+	"static",
+	"assets",
+	"admin",
+	"auth",
+	"login",
+	"logout",
+	"metrics",
+	"favicon.ico",
+	"robots.txt",
+}
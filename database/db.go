@@ -54,10 +54,32 @@ func Initialize(dbConfig Config) (*gorm.DB, error) {
 	}
 
 	// Auto Migrate the schema
-	//&models.User{},
-	if err := db.AutoMigrate(&models.Shorten{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.Shorten{},
+		&models.RedirectRule{},
+		&models.Destination{},
+		&models.Tag{},
+		&models.ImportJob{},
+		&models.Click{},
+		&models.ClickRollup{},
+		&models.APIKey{},
+		&models.User{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.RecoveryCode{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
 	}
 
+	// Codes used to be unique per workspace and domain, which let two
+	// workspaces on one domain claim the same code
+	if db.Migrator().HasIndex(&models.Shorten{}, "idx_shortens_code") {
+		if err := db.Migrator().DropIndex(&models.Shorten{}, "idx_shortens_code"); err != nil {
+			return nil, fmt.Errorf("failed to drop the old short code index: %w", err)
+		}
+	}
+
 	return db, nil
 }
@@ -24,25 +24,62 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
-            "get": {
-                "description": "returns JSON object with health statuses.",
-                "produces": [
+        "/auth/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Turns off two-factor authentication for the current user after verifying a TOTP or recovery code. Not allowed while auth.enable2FA is on.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "auth"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorDisableRequest"
+                        }
+                    }
                 ],
-                "summary": "checks app and database health",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content - two-factor authentication disabled"
+                    },
+                    "400": {
+                        "description": "Not enabled or not a user session",
                         "schema": {
-                            "$ref": "#/definitions/models.HealthResponse"
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or missing code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Two-factor authentication is enforced",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many invalid codes, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
@@ -50,9 +87,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/shorten": {
+        "/auth/2fa/enable": {
             "post": {
-                "description": "Creates a new shortened URL from a long URL, with optional custom code and expiration. If no custom code is provided, one will be generated.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confirms enrollment with a code from the authenticator app and returns ten single-use recovery codes. They are only shown once. If auth.enable2FA is on, log in again with a code to obtain unrestricted tokens.",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,29 +102,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorten"
+                    "auth"
                 ],
-                "summary": "Create a shortened URL",
+                "summary": "Confirm two-factor enrollment",
                 "parameters": [
                     {
-                        "description": "URL to shorten",
+                        "description": "Current TOTP code",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ShortenRequest"
+                            "$ref": "#/definitions/models.TwoFactorCodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created shortened URL",
+                    "200": {
+                        "description": "Two-factor authentication enabled",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                            "$ref": "#/definitions/models.APIResponse-models_RecoveryCodesData"
                         }
                     },
                     "400": {
-                        "description": "Invalid request format or short code already exists",
+                        "description": "Enrollment not started or not a user session",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many invalid codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
@@ -96,9 +156,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/shorten/lookup": {
+        "/auth/2fa/recovery-codes": {
             "post": {
-                "description": "Checks if an original URL already has a short code and optionally creates one if it doesn't exist",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces all recovery codes of the current user with ten new ones",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,41 +171,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorten"
+                    "auth"
                 ],
-                "summary": "Check if a URL is already shortened",
+                "summary": "Regenerate recovery codes",
                 "parameters": [
                     {
-                        "description": "Original URL to check",
+                        "description": "Current TOTP code",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.GetByOriginalURLRequest"
+                            "$ref": "#/definitions/models.TwoFactorCodeRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved shortened URL information",
+                        "description": "New recovery codes",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                            "$ref": "#/definitions/models.APIResponse-models_RecoveryCodesData"
                         }
                     },
-                    "201": {
-                        "description": "Successfully created new shortened URL",
+                    "400": {
+                        "description": "Not enabled or not a user session",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                            "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format",
+                    "401": {
+                        "description": "Invalid code",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
-                    "404": {
-                        "description": "Original URL not found and createIfNotExists is false",
+                    "429": {
+                        "description": "Too many invalid codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
@@ -154,48 +219,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/shorten/{code}": {
-            "get": {
-                "description": "Redirects to the original URL from a short code",
-                "tags": [
-                    "shorten"
-                ],
-                "summary": "Redirect to original URL",
-                "parameters": [
+        "/auth/2fa/setup": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Short code identifier",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Generates a TOTP secret for the current user and returns it as a secret, an otpauth:// URI and a QR code (PNG data URI). Two-factor authentication is not active until confirmed via /auth/2fa/enable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start two-factor enrollment",
                 "responses": {
-                    "302": {
-                        "description": "Found - Redirects to the original URL",
-                        "headers": {
-                            "Location": {
-                                "type": "string",
-                                "description": "The URL to redirect to"
-                            }
+                    "200": {
+                        "description": "Enrollment started",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_TwoFactorSetupData"
                         }
                     },
                     "400": {
-                        "description": "Bad request - missing code parameter",
+                        "description": "Not a user session",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
-                    "404": {
-                        "description": "Short URL not found or has expired",
+                    "409": {
+                        "description": "Two-factor authentication already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Updates an existing shortened URL by its short code",
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Exchanges a username and password for an access token (valid for auth.tokenExpiration hours) and a refresh token (valid for auth.sessionTimeout days). Requires auth.enableLocal. Users with two-factor authentication must also send totpCode or recoveryCode. When auth.enable2FA is on and the user has not enrolled, the tokens are only good for /auth/2fa enrollment (twoFactorSetupRequired).",
                 "consumes": [
                     "application/json"
                 ],
@@ -203,32 +272,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shorten"
+                    "auth"
                 ],
-                "summary": "Update a shortened URL",
+                "summary": "Log in with a local account",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Short code identifier",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated URL data",
+                        "description": "Credentials",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ShortenRequest"
+                            "$ref": "#/definitions/models.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated shortened URL",
+                        "description": "Successfully logged in",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                            "$ref": "#/definitions/models.APIResponse-models_TokenData"
                         }
                     },
                     "400": {
@@ -237,8 +299,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
-                    "404": {
-                        "description": "Short URL not found",
+                    "401": {
+                        "description": "Invalid username, password or two-factor code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Local authentication is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many invalid two-factor codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
@@ -250,34 +324,45 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes an existing shortened URL by its short code",
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the access token used for this request and, if given, the refresh token of the session. Set allSessions to revoke every refresh token of the user.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "shorten"
+                    "auth"
                 ],
-                "summary": "Delete a shortened URL",
+                "summary": "Log out",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Short code identifier",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Session to end",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.LogoutRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content - URL successfully deleted"
+                        "description": "No Content - logged out"
                     },
                     "400": {
-                        "description": "Bad request - missing code parameter",
+                        "description": "Credential is not a user session",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
                     },
-                    "404": {
-                        "description": "Short URL not found",
+                    "401": {
+                        "description": "Missing or invalid credentials",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse-error"
                         }
@@ -290,194 +375,3705 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.APIResponse-models_ShortenData": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.ShortenData"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "Operation successful"
-                },
-                "success": {
-                    "type": "boolean",
-                    "example": true
-                }
-            }
         },
-        "models.ErrorResponse-error": {
-            "type": "object",
-            "properties": {
-                "details": {},
-                "message": {
-                    "type": "string",
-                    "example": "This is a pretty message"
-                },
-                "request_id": {
-                    "type": "string"
-                },
-                "statusCode": {
-                    "type": "integer",
-                    "example": 201
-                },
-                "timestamp": {
-                    "type": "string"
-                },
-                "type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.ErrorType"
+        "/auth/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the user or API key that authenticated this request, with its scopes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get the current principal",
+                "responses": {
+                    "200": {
+                        "description": "Current principal",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_Principal"
                         }
-                    ],
-                    "example": "FAILED_CHECK"
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
                 }
             }
         },
-        "models.ErrorType": {
-            "type": "string",
-            "enum": [
-                "FAILED_CHECK",
-                "UNAUTHORIZED",
-                "NOT_FOUND",
-                "BAD_REQUEST",
-                "INTERNAL_ERROR",
-                "FORBIDDEN",
-                "CONFLICT",
-                "VALIDATION_ERROR",
-                "RATE_LIMITED",
-                "TIMEOUT",
-                "SERVICE_UNAVAILABLE",
-                "UNPROCESSABLE_ENTITY"
-            ],
-            "x-enum-varnames": [
-                "ErrorTypeFailedCheck",
-                "ErrorTypeUnauthorized",
-                "ErrorTypeNotFound",
-                "ErrorTypeBadRequest",
-                "ErrorTypeInternalError",
-                "ErrorTypeForbidden",
-                "ErrorTypeConflict",
-                "ErrorTypeValidation",
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new token pair. The presented refresh token is revoked; reusing it revokes every session of the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh an access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully refreshed tokens",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_TokenData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired or revoked refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Local authentication is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the links of the caller's workspace, or with type=clicks their click events, as CSV (default), JSON Lines or a JSON array, for data warehouses and backups. Links are selected with the same filters as the listing. Click events can further be limited to a time range with from and to, and require the analytics:read scope. Rows are ordered by ID and streamed in batches. If the export fails midway, the connection is aborted so the download fails.\nCSV link exports hold the link fields as columns, with utm, tags, rules and destinations as JSON. Cells starting with =, +, -, @, a tab or a carriage return are prefixed with ' so spreadsheets do not run them as formulas. JSON exports hold the same objects as GET /shorten.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/json"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export links or click events",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "links",
+                            "clicks"
+                        ],
+                        "type": "string",
+                        "default": "links",
+                        "description": "What to export",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links whose original URL contains this text",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created at or after this time (RFC3339 or YYYY-MM-DD)",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created before this time (RFC3339 or YYYY-MM-DD)",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only expired (true) or only unexpired (false) links",
+                        "name": "expired",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links carrying this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only click events at or after this time (RFC3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only click events before this time (RFC3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing links:read scope, or analytics:read for click events",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "returns JSON object with health statuses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "checks app and database health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Uploads a Bitly or YOURLS export (CSV or JSON, up to 32 MiB) and imports its links into the current workspace in the background. Links keep their short code, creation date and click count. Links whose code is already taken are reported as conflicts and skipped. Poll the returned job with GET /imports/{id} until its status is no longer running.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import links from another shortener",
+                "parameters": [
+                    {
+                        "enum": [
+                            "bitly",
+                            "yourls"
+                        ],
+                        "type": "string",
+                        "description": "Shortener the export comes from",
+                        "name": "source",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "File format, defaults to the file extension",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Export file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Import started",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file, unknown source or format, or unreadable export",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not a member of any workspace",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "413": {
+                        "description": "Export file larger than 32 MiB",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns an import job: its status (running, completed or failed), how many links were imported, conflicted or failed so far, and the first 1000 issues by row. Only the user or API key that started the import, or a holder of links:admin, can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Get the progress of an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import job",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid job ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Import job not found or started by someone else",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists all API keys, including revoked and expired ones. Key material is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "Successfully listed API keys",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-array_models_APIKey"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing keys:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new API key in the current workspace with the given scopes, which must be a subset of the caller's. The plaintext key is only returned in this response; store it securely.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Key name, scopes and optional expiry",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_APIKeyData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or unknown scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing keys:admin scope, or a requested scope is not held by the caller",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes an API key. Revoked keys are kept for auditing but can no longer authenticate.",
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content - key successfully revoked"
+                    },
+                    "400": {
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing keys:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a page of shortened URLs. Page size is capped by app.maxPageSize. Results can be filtered by creation date, expiry state, tag and a substring of the original URL, and sorted by creation date or click count. Every link of the caller's workspace is listed, whoever created it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "List shortened URLs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per page, capped by app.maxPageSize",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Substring of the original URL (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created at or after, RFC3339 or YYYY-MM-DD",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created before, RFC3339 or YYYY-MM-DD",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only expired (true) or only unexpired (false) links",
+                        "name": "expired",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links carrying this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "clickCount"
+                        ],
+                        "type": "string",
+                        "default": "createdAt",
+                        "description": "Sort key",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully listed shortened URLs",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_PaginatedData-models_ShortenData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new shortened URL from a long URL, with optional custom code and expiration. If no custom code is provided, one will be generated. The link is owned by the calling user or API key. A password makes visitors unlock the link before being redirected. maxClicks stops the link after that many redirects, 1 for a one-time link. activatesAt keeps the link dormant until a start time; expiresAt sets an absolute end time instead of expiresAfter. fallbackUrl receives visitors once the link has expired, is disabled or has used up its clicks. rules send visitors on iOS, Android or desktop, or from a country, to another URL such as an app store listing, deep link or regional site. destinations split the remaining visitors across weighted URLs for A/B tests. utm parameters, and those of the workspace campaign template named by campaign, are added to the destination on redirect. passthrough appends the path and query string after the code to the destination. tags label the link; missing tags are created in the workspace.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Create a shortened URL",
+                "parameters": [
+                    {
+                        "description": "URL to shorten",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShortenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created shortened URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format, or short code is invalid, already exists or is reserved, expiresAt is not after activatesAt, or campaign is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates up to 500 shortened URLs in one call. Every link is validated and created on its own and gets a result with its data or a typed error, in request order. With atomic, all links are created in one transaction or none is. Answers 201 when every link was created, 207 when only some were and 422 when none was.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Create several shortened URLs",
+                "parameters": [
+                    {
+                        "description": "Links to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchShortenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Every link was created",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_BatchShortenResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Some links were created, see results",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_BatchShortenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or more than 500 links",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not a member of any workspace",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "422": {
+                        "description": "No link was created, see results",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_BatchShortenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies one action to every link of the caller selected by codes, by filter or by both (links must then match both). delete removes the links, expire ends them now (links that already expired are left alone) and retag adds addTags and removes removeTags. The filter matches links with a tag, created before a time (RFC3339 or YYYY-MM-DD), or whose original URL is on a domain or its subdomains. With dryRun the links are only listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Delete, expire or retag many links",
+                "parameters": [
+                    {
+                        "description": "Action, selection and options",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Links changed, or that would change on a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_BulkLinkResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format, empty selection or retag without tags",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten/lookup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks if an original URL already has a short code and optionally creates one if it doesn't exist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Check if a URL is already shortened",
+                "parameters": [
+                    {
+                        "description": "Original URL to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GetByOriginalURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved shortened URL information",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                        }
+                    },
+                    "201": {
+                        "description": "Successfully created new shortened URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format, or custom code is invalid, already exists or is reserved",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Original URL not found and createIfNotExists is false",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten/{code}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates an existing shortened URL by its short code. Only the owner or a holder of links:admin can update a link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Update a shortened URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated URL data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShortenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated shortened URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format, expiresAt is not after activatesAt, or campaign is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found or owned by someone else",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes an existing shortened URL by its short code. Only the owner or a holder of links:admin can delete a link.",
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Delete a shortened URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content - URL successfully deleted"
+                    },
+                    "400": {
+                        "description": "Bad request - missing code parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found or owned by someone else",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten/{code}/stats/timeseries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns click counts per hour or per day between from and to. Buckets are aligned to UTC and served from pre-aggregated rollups, so the latest bucket may lag by one aggregation interval. Defaults to the last 30 days (interval=day) or the last 48 hours (interval=hour).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Get bucketed click history for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Bucket width",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start, RFC3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (exclusive), RFC3339 or YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved timeseries",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenTimeseries"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid interval or time range",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found or owned by someone else",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the tags of the caller's workspace with the number of links carrying each tag and the sum of their clicks, busiest tag first. Filter links by tag with GET /shorten?tag=.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags with their click counts",
+                "responses": {
+                    "200": {
+                        "description": "Tags with aggregate counts",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-array_models_TagStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/urls/{code}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the total click count, last access time and top referrers for a short code. Split links also get their clicks per destination in variants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Get click statistics for a short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_ShortenStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found or owned by someone else",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List local users",
+                "responses": {
+                    "200": {
+                        "description": "Successfully listed users",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-array_models_User"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing users:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a user that can log in via POST /auth/login. Passwords are stored as bcrypt hashes. The user becomes a member of the caller's workspace. Only callers holding config:admin or workspaces:admin may create admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a local user",
+                "parameters": [
+                    {
+                        "description": "New user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created user",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing users:admin scope, or config:admin or workspaces:admin for an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email already in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the workspaces the caller belongs to. Holders of workspaces:admin see every workspace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "List workspaces",
+                "responses": {
+                    "200": {
+                        "description": "Successfully listed workspaces",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-array_models_Workspace"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a workspace. A user creating it becomes its owner. Links of a workspace with a domain are served on that domain.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Create a workspace",
+                "parameters": [
+                    {
+                        "description": "Workspace name, slug, optional domain and settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created workspace",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or slug",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug or domain already in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Get a workspace",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workspace found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid workspace ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the name, slug, domain and settings of a workspace. Changing the domain moves the workspace's links to it, unless another workspace already uses one of their codes there. Requires workspaces:admin, or members:admin (owner role) in that workspace; only workspaces:admin may change the domain.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Update a workspace",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Workspace name, slug, optional domain and settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated workspace",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-models_Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format, slug or domain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin or members:admin scope, or workspaces:admin for a domain change",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug, domain or link codes on the domain already in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the members of a workspace with their roles",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "List workspace members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully listed members",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse-array_models_WorkspaceMemberData"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid workspace ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin or members:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a user to a workspace with a role: viewer, editor (default), admin or owner. Existing members keep their role; use PUT to change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Add a workspace member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddWorkspaceMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content - user is a member"
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin or members:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the role of a workspace member. The last owner cannot be demoted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Change a member's role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWorkspaceMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content - role changed"
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin or members:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found or user is not a member",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "The user is the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Remove a workspace member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content - user removed"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing workspaces:admin or members:admin scope",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Workspace not found or user is not a member",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "409": {
+                        "description": "The user is the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            }
+        },
+        "/{code}": {
+            "get": {
+                "description": "Redirects to the original URL from a short code. This route is served at the root of the host (/{code}), outside the /api/v1 base path. Links with passthrough also resolve at /{code}/any/path: the trailing path is appended to the destination's path and the query string is merged into the destination's, where parameters the destination already has win. Without passthrough, extra path segments answer 404 and the query string is ignored. Visitors go to the first of the link's rules matching their device (from the User-Agent) and country (from the client IP and geoip.databasePath), else to one of its split destinations (picked by weight and kept in a cookie for 30 days), else to its original URL. The link's UTM parameters are added to the destination's query string unless it already has them. The status is the link's redirectStatus, else its workspace's defaultRedirectStatus, else redirect.defaultStatus (302 by default).",
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Redirect to original URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password of a protected link, for API clients",
+                        "name": "X-Link-Password",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "301": {
+                        "description": "Moved Permanently - Redirects to the original URL",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL to redirect to"
+                            }
+                        }
+                    },
+                    "302": {
+                        "description": "Found - Redirects an expired, disabled or out of clicks link to its fallback URL",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL to redirect to"
+                            }
+                        }
+                    },
+                    "307": {
+                        "description": "Temporary Redirect - Redirects to the original URL",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL to redirect to"
+                            }
+                        }
+                    },
+                    "308": {
+                        "description": "Permanent Redirect - Redirects to the original URL",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL to redirect to"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing code parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "401": {
+                        "description": "Password required or wrong; browsers get an HTML password prompt instead",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "403": {
+                        "description": "Link is not active yet, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found, expired, disabled or out of clicks; browsers get an HTML page instead",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong passwords, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Target of the HTML password prompt. Redirects with 303 See Other when the password is correct and shows the prompt again when it is not. Visitors are locked out for redirect.passwordLockout minutes after redirect.passwordMaxAttempts wrong passwords.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "shorten"
+                ],
+                "summary": "Unlock a password-protected link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "See Other - Redirects to the original URL",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL to redirect to"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Password prompt with an error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Short URL not found or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse-error"
+                        }
+                    },
+                    "429": {
+                        "description": "Password prompt explaining the lockout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "pk_Xb3k9QaZ"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Scope"
+                    },
+                    "example": [
+                        "links:read",
+                        "links:write"
+                    ]
+                },
+                "workspaceId": {
+                    "description": "WorkspaceID is the workspace the key was created in and operates on",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.APIKeyData": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "pk_Xb3k9QaZ7fLr2mN0pQs4tUv6wXy8zA1b"
+                }
+            }
+        },
+        "models.APIResponse-array_models_APIKey": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKey"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-array_models_TagStats": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TagStats"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-array_models_User": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-array_models_Workspace": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Workspace"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-array_models_WorkspaceMemberData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WorkspaceMemberData"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_APIKeyData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.APIKeyData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_BatchShortenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.BatchShortenResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_BulkLinkResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.BulkLinkResult"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_ImportJob": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ImportJob"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_PaginatedData-models_ShortenData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.PaginatedData-models_ShortenData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_Principal": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Principal"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_RecoveryCodesData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.RecoveryCodesData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_ShortenData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ShortenData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_ShortenStats": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ShortenStats"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_ShortenTimeseries": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ShortenTimeseries"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_TokenData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.TokenData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_TwoFactorSetupData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.TwoFactorSetupData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_User": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.APIResponse-models_Workspace": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Workspace"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.AddWorkspaceMemberRequest": {
+            "type": "object",
+            "required": [
+                "userId"
+            ],
+            "properties": {
+                "role": {
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "admin",
+                        "owner"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ],
+                    "example": "editor"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.BatchItemError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "The specified short code already exists"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ErrorType"
+                        }
+                    ],
+                    "example": "CONFLICT"
+                }
+            }
+        },
+        "models.BatchShortenRequest": {
+            "type": "object",
+            "required": [
+                "links"
+            ],
+            "properties": {
+                "atomic": {
+                    "description": "Atomic creates all links or none. By default the valid links are\ncreated even when others fail.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "links": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.ShortenRequest"
+                    }
+                }
+            }
+        },
+        "models.BatchShortenResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchShortenResult"
+                    }
+                }
+            }
+        },
+        "models.BatchShortenResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ShortenData"
+                },
+                "error": {
+                    "$ref": "#/definitions/models.BatchItemError"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.BulkAction": {
+            "type": "string",
+            "enum": [
+                "delete",
+                "expire",
+                "retag"
+            ],
+            "x-enum-varnames": [
+                "BulkActionDelete",
+                "BulkActionExpire",
+                "BulkActionRetag"
+            ]
+        },
+        "models.BulkLinkFilter": {
+            "type": "object",
+            "properties": {
+                "createdBefore": {
+                    "description": "CreatedBefore is RFC3339 or YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "domain": {
+                    "description": "Domain matches links whose original URL is on this host or one of\nits subdomains",
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "tag": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "spring-sale"
+                }
+            }
+        },
+        "models.BulkLinkRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "codes"
+            ],
+            "properties": {
+                "action": {
+                    "enum": [
+                        "delete",
+                        "expire",
+                        "retag"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkAction"
+                        }
+                    ],
+                    "example": "expire"
+                },
+                "addTags": {
+                    "description": "AddTags and RemoveTags are applied by retag",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "archived"
+                    ]
+                },
+                "codes": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc123",
+                        "def456"
+                    ]
+                },
+                "dryRun": {
+                    "description": "DryRun reports the links the operation would change without changing them",
+                    "type": "boolean",
+                    "example": true
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.BulkLinkFilter"
+                },
+                "removeTags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale"
+                    ]
+                }
+            }
+        },
+        "models.BulkLinkResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkAction"
+                        }
+                    ],
+                    "example": "expire"
+                },
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc123",
+                        "def456"
+                    ]
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "matched": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresAfter": {
+                    "description": "In days",
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.Scope"
+                    },
+                    "example": [
+                        "links:read",
+                        "links:write"
+                    ]
+                }
+            }
+        },
+        "models.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "isAdmin": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8,
+                    "example": "correct-horse-battery"
+                },
+                "role": {
+                    "description": "Role in the caller's workspace, which the new user joins. Defaults to editor.",
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "admin",
+                        "owner"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ],
+                    "example": "editor"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3,
+                    "example": "jane"
+                }
+            }
+        },
+        "models.Destination": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/landing-a"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "models.DestinationRequest": {
+            "type": "object",
+            "required": [
+                "url",
+                "weight"
+            ],
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/landing-a"
+                },
+                "weight": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 50
+                }
+            }
+        },
+        "models.Device": {
+            "type": "string",
+            "enum": [
+                "ios",
+                "android",
+                "desktop"
+            ],
+            "x-enum-varnames": [
+                "DeviceIOS",
+                "DeviceAndroid",
+                "DeviceDesktop"
+            ]
+        },
+        "models.ErrorResponse-error": {
+            "type": "object",
+            "properties": {
+                "details": {},
+                "message": {
+                    "type": "string",
+                    "example": "This is a pretty message"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ErrorType"
+                        }
+                    ],
+                    "example": "FAILED_CHECK"
+                }
+            }
+        },
+        "models.ErrorType": {
+            "type": "string",
+            "enum": [
+                "FAILED_CHECK",
+                "UNAUTHORIZED",
+                "NOT_FOUND",
+                "BAD_REQUEST",
+                "INTERNAL_ERROR",
+                "FORBIDDEN",
+                "CONFLICT",
+                "VALIDATION_ERROR",
+                "RATE_LIMITED",
+                "TIMEOUT",
+                "SERVICE_UNAVAILABLE",
+                "UNPROCESSABLE_ENTITY"
+            ],
+            "x-enum-varnames": [
+                "ErrorTypeFailedCheck",
+                "ErrorTypeUnauthorized",
+                "ErrorTypeNotFound",
+                "ErrorTypeBadRequest",
+                "ErrorTypeInternalError",
+                "ErrorTypeForbidden",
+                "ErrorTypeConflict",
+                "ErrorTypeValidation",
                 "ErrorTypeRateLimited",
                 "ErrorTypeTimeout",
                 "ErrorTypeServiceUnavailable",
                 "ErrorTypeUnprocessableEntity"
             ]
         },
-        "models.GetByOriginalURLRequest": {
+        "models.GetByOriginalURLRequest": {
+            "type": "object",
+            "required": [
+                "originalUrl"
+            ],
+            "properties": {
+                "createIfNotExists": {
+                    "type": "boolean"
+                },
+                "customCode": {
+                    "type": "string"
+                },
+                "expiresAfter": {
+                    "type": "integer"
+                },
+                "originalUrl": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "type": "object",
+            "required": [
+                "application",
+                "database",
+                "status"
+            ],
+            "properties": {
+                "application": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "database": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "status": {
+                    "description": "Overall status of the system",
+                    "type": "string",
+                    "enum": [
+                        "up",
+                        "down",
+                        "degraded"
+                    ],
+                    "example": "up"
+                }
+            }
+        },
+        "models.ImportFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "json"
+            ],
+            "x-enum-varnames": [
+                "ImportFormatCSV",
+                "ImportFormatJSON"
+            ]
+        },
+        "models.ImportIssue": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "spring"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "The short code already exists"
+                },
+                "row": {
+                    "description": "Row is the 1-based position of the link in the file, after the CSV\nheader",
+                    "type": "integer",
+                    "example": 12
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ErrorType"
+                        }
+                    ],
+                    "example": "CONFLICT"
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is why a failed job stopped early",
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportFormat"
+                        }
+                    ],
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 395
+                },
+                "issues": {
+                    "description": "Issues lists the links that were not imported and why, at most\nMaxImportIssues of them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportIssue"
+                    }
+                },
+                "processed": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "source": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportSource"
+                        }
+                    ],
+                    "example": "bitly"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportStatus"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "total": {
+                    "description": "Total is the number of links in the file. Processed counts those\nhandled so far, as Imported, Conflicts or Failed.",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportSource": {
+            "type": "string",
+            "enum": [
+                "bitly",
+                "yourls"
+            ],
+            "x-enum-varnames": [
+                "ImportSourceBitly",
+                "ImportSourceYOURLS"
+            ]
+        },
+        "models.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportStatusRunning",
+                "ImportStatusCompleted",
+                "ImportStatusFailed"
+            ]
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "correct-horse-battery"
+                },
+                "recoveryCode": {
+                    "type": "string",
+                    "example": "ABCD-EFGH-IJKL-MNOP"
+                },
+                "totpCode": {
+                    "description": "One of these is required when the user has two-factor authentication enabled",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "jane"
+                }
+            }
+        },
+        "models.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "allSessions": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": "rt_3q2+7w..."
+                }
+            }
+        },
+        "models.PaginatedData-models_ShortenData": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ShortenData"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "totalItems": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "totalPages": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.Principal": {
+            "type": "object",
+            "properties": {
+                "apiKeyId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the user's role in that workspace, empty for API keys",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Scope"
+                    }
+                },
+                "twoFactorSetupRequired": {
+                    "description": "TwoFactorSetupRequired is set when auth.enable2FA is on and the session\nwas not established with a second factor. Such sessions hold no scopes\nand may only enroll in two-factor authentication.",
+                    "type": "boolean"
+                },
+                "userId": {
+                    "type": "integer"
+                },
+                "workspaceId": {
+                    "description": "WorkspaceID is the workspace the request operates on. API keys are\nbound to one workspace; user sessions pick one per request.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RecoveryCodesData": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABCD-EFGH-IJKL-MNOP"
+                    ]
+                }
+            }
+        },
+        "models.RedirectRule": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 code, resolved from the visitor's IP",
+                    "type": "string",
+                    "example": "DE"
+                },
+                "device": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Device"
+                        }
+                    ],
+                    "example": "ios"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://apps.apple.com/app/id123456789"
+                }
+            }
+        },
+        "models.RedirectRuleRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "DE"
+                },
+                "device": {
+                    "enum": [
+                        "ios",
+                        "android",
+                        "desktop"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Device"
+                        }
+                    ],
+                    "example": "ios"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://apps.apple.com/app/id123456789"
+                }
+            }
+        },
+        "models.ReferrerCount": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "referrer": {
+                    "type": "string",
+                    "example": "https://news.ycombinator.com/"
+                }
+            }
+        },
+        "models.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string",
+                    "example": "rt_3q2+7w..."
+                }
+            }
+        },
+        "models.Role": {
+            "type": "string",
+            "enum": [
+                "viewer",
+                "editor",
+                "admin",
+                "owner"
+            ],
+            "x-enum-varnames": [
+                "RoleViewer",
+                "RoleEditor",
+                "RoleAdmin",
+                "RoleOwner"
+            ]
+        },
+        "models.RollupInterval": {
+            "type": "string",
+            "enum": [
+                "hour",
+                "day"
+            ],
+            "x-enum-varnames": [
+                "RollupIntervalHour",
+                "RollupIntervalDay"
+            ]
+        },
+        "models.Scope": {
+            "type": "string",
+            "enum": [
+                "links:read",
+                "links:write",
+                "analytics:read",
+                "config:admin",
+                "keys:admin",
+                "users:admin",
+                "links:admin",
+                "workspaces:admin",
+                "members:admin"
+            ],
+            "x-enum-varnames": [
+                "ScopeLinksRead",
+                "ScopeLinksWrite",
+                "ScopeAnalyticsRead",
+                "ScopeConfigAdmin",
+                "ScopeKeysAdmin",
+                "ScopeUsersAdmin",
+                "ScopeLinksAdmin",
+                "ScopeWorkspacesAdmin",
+                "ScopeMembersAdmin"
+            ]
+        },
+        "models.Shorten": {
+            "type": "object",
+            "required": [
+                "originalUrl"
+            ],
+            "properties": {
+                "activatesAt": {
+                    "description": "ActivatesAt keeps the link dormant until then. Zero means the link is\nactive as soon as it is created.",
+                    "type": "string"
+                },
+                "campaign": {
+                    "type": "string",
+                    "example": "spring-sale"
+                },
+                "clickCount": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "destinations": {
+                    "description": "Destinations split the visitors no rule matches across several URLs\nby weight. Without destinations, they go to OriginalURL.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Destination"
+                    }
+                },
+                "disabled": {
+                    "description": "Disabled links stop redirecting until they are enabled again",
+                    "type": "boolean",
+                    "example": false
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "go.acme.com"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "fallbackUrl": {
+                    "description": "FallbackURL is where visitors are sent once the link has expired, is\ndisabled or has used up its clicks. Empty falls back to the workspace,\nthen the global fallback.",
+                    "type": "string",
+                    "example": "https://example.com/offer-ended"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "maxClicks": {
+                    "description": "MaxClicks is the number of redirects the link serves before it stops\nresolving, 1 for a one-time link. Zero means unlimited.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "originalUrl": {
+                    "type": "string",
+                    "example": "https://example.com/some/long/path"
+                },
+                "ownerApiKeyId": {
+                    "type": "integer"
+                },
+                "ownerUserId": {
+                    "description": "The user or API key that created the link. Links created before\nownership was tracked have neither and are only visible to links:admin.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "passthrough": {
+                    "description": "Passthrough appends the path after the code and the query string of\nthe short URL to the destination, e.g. /abc123/docs?ref=x",
+                    "type": "boolean",
+                    "example": false
+                },
+                "redirectStatus": {
+                    "description": "RedirectStatus is the HTTP status of the redirect: 301, 302, 307 or\n308. Zero falls back to the workspace, then the global default.",
+                    "type": "integer",
+                    "example": 301
+                },
+                "rules": {
+                    "description": "Rules pick another destination by the visitor's device and country,\nin order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RedirectRule"
+                    }
+                },
+                "shortCode": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "tags": {
+                    "description": "Tags label the link for filtering and per-tag click counts",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "utm": {
+                    "description": "UTM parameters are added to the destination on redirect. Campaign names\na template in the workspace settings that fills the parameters UTM\nleaves empty.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UTMParams"
+                        }
+                    ]
+                },
+                "workspaceId": {
+                    "description": "Codes are unique per domain, since redirects look links up by domain\nand code alone. Domain is the workspace's domain, empty for\napp.appUrl, and follows it when it changes.",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ShortenData": {
+            "type": "object",
+            "properties": {
+                "passwordProtected": {
+                    "type": "boolean"
+                },
+                "shortUrl": {
+                    "type": "string"
+                },
+                "shorten": {
+                    "$ref": "#/definitions/models.Shorten"
+                }
+            }
+        },
+        "models.ShortenRequest": {
+            "type": "object",
+            "required": [
+                "originalUrl"
+            ],
+            "properties": {
+                "activatesAt": {
+                    "description": "ActivatesAt keeps the link dormant until this RFC3339 time",
+                    "type": "string",
+                    "example": "2025-06-01T09:00:00Z"
+                },
+                "campaign": {
+                    "description": "Campaign names a UTM template of the workspace. On update, omit it to\nkeep the current one and send \"\" to remove it.",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "spring-sale"
+                },
+                "customCode": {
+                    "type": "string"
+                },
+                "destinations": {
+                    "description": "Destinations make the link an A/B split across weighted URLs. On\nupdate, omit them to keep the current ones and send [] to remove them.",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "$ref": "#/definitions/models.DestinationRequest"
+                    }
+                },
+                "disabled": {
+                    "description": "Disabled stops the link from redirecting. Omit to keep the current state.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expiresAfter": {
+                    "description": "In days",
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt is an absolute expiry time in RFC3339 and wins over expiresAfter",
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                },
+                "fallbackUrl": {
+                    "description": "FallbackURL receives visitors once the link has expired, is disabled\nor has used up its clicks",
+                    "type": "string",
+                    "example": "https://example.com/offer-ended"
+                },
+                "maxClicks": {
+                    "description": "MaxClicks limits how often the link redirects, 1 for a one-time link.\nOn update, omit it to keep the current limit and send 0 to remove it.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "originalUrl": {
+                    "type": "string"
+                },
+                "passthrough": {
+                    "description": "Passthrough appends the path and query string of the short URL to the\ndestination. Omit it on update to keep the current setting.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "password": {
+                    "description": "Password protects the link. Visitors enter it in a prompt, or API\nclients send it in the X-Link-Password header.",
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 4,
+                    "example": "launch-day"
+                },
+                "redirectStatus": {
+                    "description": "Permanent (301, 308) redirects are cached by browsers, so repeat visits\nmay not be counted. Omit to use the workspace or global default.",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302,
+                        307,
+                        308
+                    ],
+                    "example": 302
+                },
+                "removeFallbackUrl": {
+                    "description": "RemoveFallbackURL clears the link's fallback URL on update",
+                    "type": "boolean",
+                    "example": false
+                },
+                "removePassword": {
+                    "description": "RemovePassword makes a protected link public again on update",
+                    "type": "boolean",
+                    "example": false
+                },
+                "rules": {
+                    "description": "Rules send visitors on some devices or from some countries elsewhere,\nfirst match wins. On update, omit them to keep the current rules and\nsend [] to remove them.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/models.RedirectRuleRequest"
+                    }
+                },
+                "tags": {
+                    "description": "Tags label the link. Missing tags are created in the workspace. On\nupdate, omit them to keep the current tags and send [] to remove them.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale",
+                        "newsletter"
+                    ]
+                },
+                "utm": {
+                    "description": "UTM parameters to add to the destination on redirect. On update, omit\nthem to keep the current ones and send {} to remove them.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UTMParams"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ShortenStats": {
             "type": "object",
-            "required": [
-                "originalUrl"
-            ],
             "properties": {
-                "createIfNotExists": {
-                    "type": "boolean"
+                "clicks": {
+                    "type": "integer",
+                    "example": 1000
                 },
-                "customCode": {
+                "createdAt": {
                     "type": "string"
                 },
-                "expiresAfter": {
-                    "type": "integer"
+                "lastAccessed": {
+                    "type": "string"
                 },
-                "originalUrl": {
+                "shortCode": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "topReferrers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferrerCount"
+                    }
+                },
+                "variants": {
+                    "description": "Variants breaks the clicks of a split link down by destination",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.VariantStats"
+                    }
+                }
+            }
+        },
+        "models.ShortenTimeseries": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RollupInterval"
+                        }
+                    ],
+                    "example": "day"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TimeseriesPoint"
+                    }
+                },
+                "shortCode": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "models.HealthResponse": {
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "spring-sale"
+                }
+            }
+        },
+        "models.TagStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 3400
+                },
+                "links": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "spring-sale"
+                }
+            }
+        },
+        "models.TimeseriesPoint": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TokenData": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": "rt_3q2+7w..."
+                },
+                "tokenType": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "twoFactorSetupRequired": {
+                    "description": "TwoFactorSetupRequired means the tokens are only good for enrolling in\ntwo-factor authentication; log in again with a code afterwards",
+                    "type": "boolean"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "models.TwoFactorCodeRequest": {
             "type": "object",
             "required": [
-                "application",
-                "database",
-                "status"
+                "code"
             ],
             "properties": {
-                "application": {
-                    "type": "boolean",
-                    "example": true
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "models.TwoFactorDisableRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
                 },
-                "database": {
-                    "type": "boolean",
-                    "example": true
+                "recoveryCode": {
+                    "type": "string",
+                    "example": "ABCD-EFGH-IJKL-MNOP"
+                }
+            }
+        },
+        "models.TwoFactorSetupData": {
+            "type": "object",
+            "properties": {
+                "otpauthUri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Portus:jane?issuer=Portus\u0026secret=JBSWY3DPEHPK3PXP"
                 },
-                "status": {
-                    "description": "Overall status of the system",
+                "qrCode": {
                     "type": "string",
-                    "enum": [
-                        "up",
-                        "down",
-                        "degraded"
-                    ],
-                    "example": "up"
+                    "example": "data:image/png;base64,iVBORw0KGgo..."
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
                 }
             }
         },
-        "models.Shorten": {
+        "models.UTMParams": {
+            "type": "object",
+            "properties": {
+                "campaign": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "spring-sale"
+                },
+                "content": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "header-link"
+                },
+                "medium": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "email"
+                },
+                "source": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "newsletter"
+                },
+                "term": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "running shoes"
+                }
+            }
+        },
+        "models.UpdateWorkspaceMemberRequest": {
             "type": "object",
             "required": [
-                "originalUrl"
+                "role"
             ],
             "properties": {
-                "clickCount": {
+                "role": {
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "admin",
+                        "owner"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ],
+                    "example": "admin"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "id": {
                     "type": "integer",
-                    "example": 0
+                    "example": 1
                 },
-                "createdAt": {
+                "isAdmin": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "lastLoginAt": {
                     "type": "string"
                 },
-                "expiresAt": {
+                "twoFactorEnabled": {
+                    "description": "TOTP two-factor authentication. TOTPSecret is set during enrollment\nand only trusted once TOTPEnabled is set; TOTPLastStep blocks replays.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "jane"
+                }
+            }
+        },
+        "models.VariantStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 420
+                },
+                "destinationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/landing-a"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "models.Workspace": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
                     "type": "string"
                 },
+                "domain": {
+                    "description": "Domain is the host the workspace's short links are served on, e.g.\n\"go.acme.com\". Without one, links are served on app.appUrl.",
+                    "type": "string",
+                    "example": "go.acme.com"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "originalUrl": {
+                "name": {
                     "type": "string",
-                    "example": "https://example.com/some/long/path"
+                    "example": "Acme Shoes"
                 },
-                "shortCode": {
+                "settings": {
+                    "$ref": "#/definitions/models.WorkspaceSettings"
+                },
+                "slug": {
                     "type": "string",
-                    "example": "abc123"
+                    "example": "acme-shoes"
                 },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "models.ShortenData": {
+        "models.WorkspaceMemberData": {
             "type": "object",
             "properties": {
-                "shortUrl": {
-                    "type": "string"
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ],
+                    "example": "editor"
                 },
-                "shorten": {
-                    "$ref": "#/definitions/models.Shorten"
+                "user": {
+                    "$ref": "#/definitions/models.User"
                 }
             }
         },
-        "models.ShortenRequest": {
+        "models.WorkspaceRequest": {
             "type": "object",
             "required": [
-                "originalUrl"
+                "name",
+                "slug"
             ],
             "properties": {
-                "customCode": {
-                    "type": "string"
+                "domain": {
+                    "type": "string",
+                    "example": "go.acme.com"
                 },
-                "expiresAfter": {
-                    "description": "In days",
-                    "type": "integer"
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Acme Shoes"
                 },
-                "originalUrl": {
-                    "type": "string"
+                "settings": {
+                    "$ref": "#/definitions/models.WorkspaceSettings"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "acme-shoes"
+                }
+            }
+        },
+        "models.WorkspaceSettings": {
+            "type": "object",
+            "required": [
+                "campaigns"
+            ],
+            "properties": {
+                "campaigns": {
+                    "description": "Campaigns are named UTM templates links can refer to by name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.UTMParams"
+                    }
+                },
+                "defaultExpiresAfter": {
+                    "description": "DefaultExpiresAfter applies to links created without expiresAfter, in days",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 30
+                },
+                "defaultRedirectStatus": {
+                    "description": "DefaultRedirectStatus applies to links without a redirect status.\nZero falls back to redirect.defaultStatus.",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302,
+                        307,
+                        308
+                    ],
+                    "example": 302
+                },
+                "fallbackUrl": {
+                    "description": "FallbackURL receives visitors of unavailable links without their own.\nEmpty falls back to redirect.fallbackUrl.",
+                    "type": "string",
+                    "example": "https://acme.com/expired"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key created via POST /keys. API keys and user access tokens from POST /auth/login may also be sent as \"Authorization: Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        }
     }
 }`
 
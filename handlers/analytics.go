@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// GetStats godoc
+// @Summary Get click statistics for a short URL
+// @Description Returns the total click count, last access time and top referrers for a short code. Split links also get their clicks per destination in variants.
+// @Tags analytics
+// @Security ApiKeyAuth
+// @Produce json
+// @Param code path string true "Short code identifier" example:"abc123"
+// @Success 200 {object} models.APIResponse[models.ShortenStats] "Successfully retrieved statistics"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "data": {
+//	    "shortCode": "abc123",
+//	    "clicks": 1000,
+//	    "createdAt": "2023-01-01T00:00:00Z",
+//	    "lastAccessed": "2023-01-01T00:00:00Z",
+//	    "topReferrers": [
+//	      { "referrer": "https://news.ycombinator.com/", "clicks": 420 },
+//	      { "referrer": "direct", "clicks": 310 }
+//	    ]
+//	  },
+//	  "message": "Statistics retrieved successfully"
+//	}
+//
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or owned by someone else"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /urls/{code}/stats [get]
+func (h *AnalyticsHandler) GetStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	code := c.Param("code")
+	if code == "" {
+		log.Warn().Msg("Missing short code in stats request")
+		utils.RespondBadRequest(c, nil, "Short code is required")
+		return
+	}
+
+	var stats *models.ShortenStats
+	stats, err := h.service.GetStats(ctx, code)
+	if err != nil {
+		if errors.Is(err, services.ErrShortURLNotFound) {
+			log.Warn().Str("code", code).Msg("Short URL not found for stats")
+			utils.RespondNotFound(c, err, "The specified short URL was not found")
+		} else {
+			log.Error().Err(err).Str("code", code).Msg("Failed to retrieve statistics")
+			utils.RespondInternalError(c, err, "Failed to retrieve statistics")
+		}
+		return
+	}
+
+	utils.RespondOK(c, stats, "Statistics retrieved successfully")
+}
+
+// GetTimeseries godoc
+// @Summary Get bucketed click history for a short URL
+// @Description Returns click counts per hour or per day between from and to. Buckets are aligned to UTC and served from pre-aggregated rollups, so the latest bucket may lag by one aggregation interval. Defaults to the last 30 days (interval=day) or the last 48 hours (interval=hour).
+// @Tags analytics
+// @Security ApiKeyAuth
+// @Produce json
+// @Param code path string true "Short code identifier" example:"abc123"
+// @Param interval query string false "Bucket width" Enums(hour, day) default(day)
+// @Param from query string false "Range start, RFC3339 or YYYY-MM-DD" example:"2023-01-01"
+// @Param to query string false "Range end (exclusive), RFC3339 or YYYY-MM-DD" example:"2023-02-01"
+// @Success 200 {object} models.APIResponse[models.ShortenTimeseries] "Successfully retrieved timeseries"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "data": {
+//	    "shortCode": "abc123",
+//	    "interval": "day",
+//	    "from": "2023-01-01T00:00:00Z",
+//	    "to": "2023-01-03T00:00:00Z",
+//	    "points": [
+//	      { "timestamp": "2023-01-01T00:00:00Z", "clicks": 12 },
+//	      { "timestamp": "2023-01-02T00:00:00Z", "clicks": 0 }
+//	    ]
+//	  },
+//	  "message": "Timeseries retrieved successfully"
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid interval or time range"
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or owned by someone else"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /shorten/{code}/stats/timeseries [get]
+func (h *AnalyticsHandler) GetTimeseries(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	code := c.Param("code")
+	if code == "" {
+		log.Warn().Msg("Missing short code in timeseries request")
+		utils.RespondBadRequest(c, nil, "Short code is required")
+		return
+	}
+
+	var query models.TimeseriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.Error().Err(err).Str("code", code).Msg("Invalid timeseries query")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	interval := models.RollupIntervalDay
+	if query.Interval != "" {
+		interval = models.RollupInterval(query.Interval)
+	}
+
+	to := time.Now()
+	if query.To != "" {
+		parsed, err := utils.ParseTimeParam(query.To)
+		if err != nil {
+			utils.RespondBadRequest(c, err, "Invalid 'to' parameter")
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if interval == models.RollupIntervalHour {
+		from = to.Add(-48 * time.Hour)
+	}
+	if query.From != "" {
+		parsed, err := utils.ParseTimeParam(query.From)
+		if err != nil {
+			utils.RespondBadRequest(c, err, "Invalid 'from' parameter")
+			return
+		}
+		from = parsed
+	}
+
+	var timeseries *models.ShortenTimeseries
+	timeseries, err := h.service.GetTimeseries(ctx, code, interval, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShortURLNotFound):
+			log.Warn().Str("code", code).Msg("Short URL not found for timeseries")
+			utils.RespondNotFound(c, err, "The specified short URL was not found")
+		case errors.Is(err, services.ErrInvalidTimeRange):
+			utils.RespondBadRequest(c, err, err.Error())
+		default:
+			log.Error().Err(err).Str("code", code).Msg("Failed to retrieve timeseries")
+			utils.RespondInternalError(c, err, "Failed to retrieve timeseries")
+		}
+		return
+	}
+
+	utils.RespondOK(c, timeseries, "Timeseries retrieved successfully")
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create an API key
+// @Description Creates a new API key in the current workspace with the given scopes, which must be a subset of the caller's. The plaintext key is only returned in this response; store it securely.
+// @Tags keys
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CreateAPIKeyRequest true "Key name, scopes and optional expiry"
+// @Example request
+//
+//	{
+//	  "name": "CI pipeline",
+//	  "scopes": ["links:read", "links:write"],
+//	  "expiresAfter": 90
+//	}
+//
+// @Success 201 {object} models.APIResponse[models.APIKeyData] "Successfully created API key"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format or unknown scope"
+// @Failure 401 {object} models.ErrorResponse[error] "Missing or invalid credentials"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing keys:admin scope, or a requested scope is not held by the caller"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for API key creation")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.Create(ctx, req)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownScope) {
+			utils.RespondBadRequest(c, err, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrScopeNotHeld) {
+			utils.RespondForbidden(c, err, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrNoWorkspace) {
+			utils.RespondForbidden(c, err, "You are not a member of any workspace")
+			return
+		}
+
+		log.Error().Err(err).Str("name", req.Name).Msg("Failed to create API key")
+		utils.RespondInternalError(c, err, "Failed to create API key")
+		return
+	}
+
+	log.Info().Uint64("apiKeyId", result.APIKey.ID).Str("prefix", result.APIKey.Prefix).Msg("Created API key")
+
+	utils.RespondCreated(c, result, "API key created successfully")
+}
+
+// List godoc
+// @Summary List API keys
+// @Description Lists all API keys, including revoked and expired ones. Key material is never returned.
+// @Tags keys
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[[]models.APIKey] "Successfully listed API keys"
+// @Failure 401 {object} models.ErrorResponse[error] "Missing or invalid credentials"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing keys:admin scope"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	keys, err := h.service.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list API keys")
+		utils.RespondInternalError(c, err, "Failed to list API keys")
+		return
+	}
+
+	utils.RespondOK(c, keys, "API keys retrieved successfully")
+}
+
+// Revoke godoc
+// @Summary Revoke an API key
+// @Description Revokes an API key. Revoked keys are kept for auditing but can no longer authenticate.
+// @Tags keys
+// @Security ApiKeyAuth
+// @Param id path int true "API key ID" example:"1"
+// @Success 204 "No Content - key successfully revoked"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid key ID"
+// @Failure 401 {object} models.ErrorResponse[error] "Missing or invalid credentials"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing keys:admin scope"
+// @Failure 404 {object} models.ErrorResponse[error] "API key not found"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondBadRequest(c, err, "Invalid API key ID")
+		return
+	}
+
+	if err := h.service.Revoke(ctx, id); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			utils.RespondNotFound(c, err, "The specified API key was not found")
+		} else {
+			log.Error().Err(err).Uint64("apiKeyId", id).Msg("Failed to revoke API key")
+			utils.RespondInternalError(c, err, "Failed to revoke API key")
+		}
+		return
+	}
+
+	log.Info().Uint64("apiKeyId", id).Msg("Revoked API key")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+}
+
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// Login godoc
+// @Summary Log in with a local account
+// @Description Exchanges a username and password for an access token (valid for auth.tokenExpiration hours) and a refresh token (valid for auth.sessionTimeout days). Requires auth.enableLocal. Users with two-factor authentication must also send totpCode or recoveryCode. When auth.enable2FA is on and the user has not enrolled, the tokens are only good for /auth/2fa enrollment (twoFactorSetupRequired).
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.LoginRequest true "Credentials"
+// @Example request
+//
+//	{
+//	  "username": "jane",
+//	  "password": "correct-horse-battery"
+//	}
+//
+// @Success 200 {object} models.APIResponse[models.TokenData] "Successfully logged in"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 401 {object} models.ErrorResponse[error] "Invalid username, password or two-factor code"
+// @Failure 403 {object} models.ErrorResponse[error] "Local authentication is disabled"
+// @Failure 429 {object} models.ErrorResponse[error] "Too many invalid two-factor codes, see Retry-After"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for login")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.Login(ctx, req)
+	if err != nil {
+		h.respondAuthError(c, err, req.Username)
+		return
+	}
+
+	log.Info().Str("username", req.Username).Msg("User logged in")
+
+	utils.RespondOK(c, result, "Logged in successfully")
+}
+
+// Refresh godoc
+// @Summary Refresh an access token
+// @Description Exchanges a refresh token for a new token pair. The presented refresh token is revoked; reusing it revokes every session of the user.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.APIResponse[models.TokenData] "Successfully refreshed tokens"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 401 {object} models.ErrorResponse[error] "Invalid, expired or revoked refresh token"
+// @Failure 403 {object} models.ErrorResponse[error] "Local authentication is disabled"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for token refresh")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		h.respondAuthError(c, err, "")
+		return
+	}
+
+	utils.RespondOK(c, result, "Tokens refreshed successfully")
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revokes the access token used for this request and, if given, the refresh token of the session. Set allSessions to revoke every refresh token of the user.
+// @Tags auth
+// @Accept json
+// @Security ApiKeyAuth
+// @Param request body models.LogoutRequest false "Session to end"
+// @Success 204 "No Content - logged out"
+// @Failure 400 {object} models.ErrorResponse[error] "Credential is not a user session"
+// @Failure 401 {object} models.ErrorResponse[error] "Missing or invalid credentials"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error().Err(err).Msg("Invalid request format for logout")
+			utils.RespondValidationError(c, err)
+			return
+		}
+	}
+
+	principal := utils.PrincipalFromContext(ctx)
+	if err := h.service.Logout(ctx, principal, req); err != nil {
+		if errors.Is(err, services.ErrNotUserSession) {
+			utils.RespondBadRequest(c, err, "Only user sessions can log out; revoke API keys instead")
+			return
+		}
+
+		log.Error().Err(err).Msg("Failed to log out")
+		utils.RespondInternalError(c, err, "Failed to log out")
+		return
+	}
+
+	log.Info().Str("principal", principal.Name).Bool("allSessions", req.AllSessions).Msg("User logged out")
+	c.Status(http.StatusNoContent)
+}
+
+// Me godoc
+// @Summary Get the current principal
+// @Description Returns the user or API key that authenticated this request, with its scopes
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[models.Principal] "Current principal"
+// @Failure 401 {object} models.ErrorResponse[error] "Missing or invalid credentials"
+// @Router /auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal := utils.PrincipalFromContext(c.Request.Context())
+	utils.RespondOK(c, principal)
+}
+
+// respondAuthError maps login and refresh failures onto HTTP responses
+func (h *AuthHandler) respondAuthError(c *gin.Context, err error, username string) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	var locked *services.TwoFactorLockedError
+	switch {
+	case errors.Is(err, services.ErrLocalAuthDisabled):
+		utils.RespondForbidden(c, err, "Local authentication is disabled")
+	case errors.Is(err, services.ErrInvalidCredentials):
+		log.Warn().Str("username", username).Msg("Failed login attempt")
+		utils.RespondUnauthorized(c, err, "Invalid username or password")
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		utils.RespondUnauthorized(c, err, "A two-factor code or recovery code is required")
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		log.Warn().Str("username", username).Msg("Invalid two-factor code")
+		utils.RespondUnauthorized(c, err, "The two-factor code is invalid or was already used")
+	case errors.As(err, &locked):
+		log.Warn().Str("username", username).Msg("Locked out of two-factor verification")
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds()+0.5)))
+		utils.RespondTooManyRequests(c, err, "Too many invalid two-factor codes, please try again later")
+	case errors.Is(err, services.ErrInvalidToken):
+		utils.RespondUnauthorized(c, err, "The refresh token is invalid, expired or revoked")
+	default:
+		log.Error().Err(err).Msg("Authentication failed")
+		utils.RespondInternalError(c, err, "Authentication failed")
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds the export files imports accept, in bytes
+const maxImportSize = 32 << 20
+
+type ImportHandler struct {
+	service services.ImportService
+}
+
+func NewImportHandler(service services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+	}
+}
+
+// Start godoc
+// @Summary Import links from another shortener
+// @Description Uploads a Bitly or YOURLS export (CSV or JSON, up to 32 MiB) and imports its links into the current workspace in the background. Links keep their short code, creation date and click count. Links whose code is already taken are reported as conflicts and skipped. Poll the returned job with GET /imports/{id} until its status is no longer running.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param source formData string true "Shortener the export comes from" Enums(bitly, yourls)
+// @Param format formData string false "File format, defaults to the file extension" Enums(csv, json)
+// @Param file formData file true "Export file"
+// @Success 202 {object} models.APIResponse[models.ImportJob] "Import started"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "message": "Import started",
+//	  "data": {
+//	    "id": 7,
+//	    "source": "bitly",
+//	    "format": "csv",
+//	    "status": "running",
+//	    "total": 1200,
+//	    "processed": 0,
+//	    "imported": 0,
+//	    "conflicts": 0,
+//	    "failed": 0,
+//	    "issues": []
+//	  }
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Missing file, unknown source or format, or unreadable export"
+// @Failure 403 {object} models.ErrorResponse[error] "Caller is not a member of any workspace"
+// @Failure 413 {object} models.ErrorResponse[error] "Export file larger than 32 MiB"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /imports [post]
+func (h *ImportHandler) Start(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var req models.ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondWithError(c, http.StatusRequestEntityTooLarge, err, "The export file is larger than 32 MiB")
+			return
+		}
+		log.Error().Err(err).Msg("Invalid import request")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondBadRequest(c, err, "An export file is required")
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = models.ImportFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), "."))
+		if req.Format != models.ImportFormatCSV && req.Format != models.ImportFormatJSON {
+			utils.RespondBadRequest(c, nil, "Set format to csv or json")
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open uploaded export")
+		utils.RespondInternalError(c, err, "Failed to read the export file")
+		return
+	}
+	defer file.Close()
+
+	log.Info().Str("source", string(req.Source)).Str("format", string(req.Format)).Int64("size", header.Size).Msg("Starting import")
+
+	job, err := h.service.Start(ctx, req, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidImport):
+			log.Warn().Err(err).Msg("Unreadable export file")
+			utils.RespondBadRequest(c, err, err.Error())
+		case errors.Is(err, services.ErrNoWorkspace):
+			utils.RespondForbidden(c, err, "You are not a member of any workspace")
+		default:
+			log.Error().Err(err).Msg("Failed to start import")
+			utils.RespondInternalError(c, err, "Failed to start import")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusAccepted, *job, "Import started")
+}
+
+// Get godoc
+// @Summary Get the progress of an import
+// @Description Returns an import job: its status (running, completed or failed), how many links were imported, conflicted or failed so far, and the first 1000 issues by row. Only the user or API key that started the import, or a holder of links:admin, can see it.
+// @Tags imports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Import job ID" example:"7"
+// @Success 200 {object} models.APIResponse[models.ImportJob] "Import job"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "message": "Import job retrieved successfully",
+//	  "data": {
+//	    "id": 7,
+//	    "source": "bitly",
+//	    "format": "csv",
+//	    "status": "completed",
+//	    "total": 1200,
+//	    "processed": 1200,
+//	    "imported": 1195,
+//	    "conflicts": 3,
+//	    "failed": 2,
+//	    "issues": [
+//	      {"row": 12, "code": "spring", "type": "CONFLICT", "message": "The short code already exists"}
+//	    ]
+//	  }
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid job ID"
+// @Failure 404 {object} models.ErrorResponse[error] "Import job not found or started by someone else"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /imports/{id} [get]
+func (h *ImportHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondBadRequest(c, err, "Invalid import job ID")
+		return
+	}
+
+	job, err := h.service.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, services.ErrImportNotFound) {
+			utils.RespondNotFound(c, err, "The specified import job was not found")
+		} else {
+			log.Error().Err(err).Uint64("importId", id).Msg("Failed to get import job")
+			utils.RespondInternalError(c, err, "Failed to get import job")
+		}
+		return
+	}
+
+	utils.RespondOK(c, *job, "Import job retrieved successfully")
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"portus/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// linkPasswordHeader carries the password of a protected link for API clients
+const linkPasswordHeader = "X-Link-Password"
+
+// passwordPromptTemplate is shown to browsers that open a password-protected
+// link. The form posts back to the short URL, path and query included.
+var passwordPromptTemplate = template.Must(template.New("password-prompt").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f5f5f5; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+form { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.15); width: 100%; max-width: 320px; }
+h1 { font-size: 1.25rem; margin-top: 0; }
+input, button { width: 100%; box-sizing: border-box; padding: .6rem; margin-top: .75rem; font-size: 1rem; }
+.error { color: #b00020; margin: .5rem 0 0; }
+</style>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<h1>This link is password protected</h1>
+<label for="password">Enter the password to continue.</label>
+<input id="password" name="password" type="password" autocomplete="current-password" required autofocus>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// unavailablePageTemplate is shown to browsers that open an expired, disabled
+// or click-exhausted link without a fallback URL, unless
+// redirect.fallbackPage replaces it
+var unavailablePageTemplate = template.Must(template.New("unavailable-page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Link unavailable</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f5f5f5; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.15); width: 100%; max-width: 320px; }
+h1 { font-size: 1.25rem; margin-top: 0; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Message}}</h1>
+<p>The link you followed is no longer available.</p>
+</main>
+</body>
+</html>
+`))
+
+// notActivePageTemplate is shown to browsers that open a link before it
+// activates
+var notActivePageTemplate = template.Must(template.New("not-active-page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Link not active yet</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f5f5f5; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.15); width: 100%; max-width: 320px; }
+h1 { font-size: 1.25rem; margin-top: 0; }
+</style>
+</head>
+<body>
+<main>
+<h1>This link is not active yet</h1>
+<p>It becomes available on <time datetime="{{.ActivatesAt}}">{{.Display}}</time>. Please come back then.</p>
+</main>
+</body>
+</html>
+`))
+
+// wantsHTML reports whether the client is a browser rather than an API client
+func wantsHTML(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/html")
+}
+
+// renderPasswordPrompt serves the password form for the link with code
+func renderPasswordPrompt(c *gin.Context, status int, code, message string) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	err := passwordPromptTemplate.Execute(c.Writer, struct {
+		Action string
+		Error  string
+	}{
+		Action: c.Request.URL.RequestURI(),
+		Error:  message,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("code", code).Msg("Failed to render password prompt")
+	}
+}
+
+// renderUnavailablePage serves page, or the built-in page with message when
+// page is empty, to a browser that opened an unavailable link
+func renderUnavailablePage(c *gin.Context, page, message string) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusNotFound)
+
+	if page != "" {
+		if _, err := io.WriteString(c.Writer, page); err != nil {
+			log.Error().Err(err).Msg("Failed to write fallback page")
+		}
+		return
+	}
+
+	err := unavailablePageTemplate.Execute(c.Writer, struct {
+		Message string
+	}{
+		Message: message,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to render unavailable page")
+	}
+}
+
+// renderNotActivePage tells a browser when the link it opened activates
+func renderNotActivePage(c *gin.Context, activatesAt time.Time) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusForbidden)
+
+	err := notActivePageTemplate.Execute(c.Writer, struct {
+		ActivatesAt string
+		Display     string
+	}{
+		ActivatesAt: activatesAt.UTC().Format(time.RFC3339),
+		Display:     activatesAt.UTC().Format("January 2, 2006 at 15:04 UTC"),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to render not active page")
+	}
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"portus/models"
 	"portus/services"
 	"portus/utils"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type ShortenHandler struct {
@@ -21,8 +28,9 @@ func NewShortenHandler(service services.ShortenService) *ShortenHandler {
 
 // Create godoc
 // @Summary Create a shortened URL
-// @Description Creates a new shortened URL from a long URL, with optional custom code and expiration. If no custom code is provided, one will be generated.
+// @Description Creates a new shortened URL from a long URL, with optional custom code and expiration. If no custom code is provided, one will be generated. The link is owned by the calling user or API key. A password makes visitors unlock the link before being redirected. maxClicks stops the link after that many redirects, 1 for a one-time link. activatesAt keeps the link dormant until a start time; expiresAt sets an absolute end time instead of expiresAfter. fallbackUrl receives visitors once the link has expired, is disabled or has used up its clicks. rules send visitors on iOS, Android or desktop, or from a country, to another URL such as an app store listing, deep link or regional site. destinations split the remaining visitors across weighted URLs for A/B tests. utm parameters, and those of the workspace campaign template named by campaign, are added to the destination on redirect. passthrough appends the path and query string after the code to the destination. tags label the link; missing tags are created in the workspace.
 // @Tags shorten
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param request body models.ShortenRequest true "URL to shorten"
@@ -31,7 +39,8 @@ func NewShortenHandler(service services.ShortenService) *ShortenHandler {
 //	{
 //	  "originalUrl": "https://example.com/some/very/long/path/that/needs/shortening",
 //	  "customCode": "mycode",
-//	  "expiresAfter": 7
+//	  "expiresAfter": 7,
+//	  "redirectStatus": 301
 //	}
 //
 // @Success 201 {object} models.APIResponse[models.ShortenData] "Successfully created shortened URL"
@@ -49,7 +58,7 @@ func NewShortenHandler(service services.ShortenService) *ShortenHandler {
 //	  "message": "URL shortened successfully"
 //	}
 //
-// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format or short code already exists"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format, or short code is invalid, already exists or is reserved, expiresAt is not after activatesAt, or campaign is unknown"
 // @Example response
 //
 //	{
@@ -83,12 +92,39 @@ func (h *ShortenHandler) Create(c *gin.Context) {
 	result, err := h.service.Create(ctx, req)
 	if err != nil {
 		// Check if this is a code conflict error
-		if err.Error() == "short code already exists" {
+		if errors.Is(err, services.ErrShortCodeExists) {
 			log.Warn().Err(err).Str("customCode", req.CustomCode).Msg("Short code already exists")
 			utils.RespondBadRequest(c, err, "The specified short code already exists")
 			return
 		}
 
+		if errors.Is(err, services.ErrShortCodeReserved) {
+			log.Warn().Err(err).Str("customCode", req.CustomCode).Msg("Short code is reserved")
+			utils.RespondBadRequest(c, err, "The specified short code is reserved")
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidShortCode) {
+			log.Warn().Err(err).Str("customCode", req.CustomCode).Msg("Invalid short code")
+			utils.RespondBadRequest(c, err, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrNoWorkspace) {
+			utils.RespondForbidden(c, err, "You are not a member of any workspace")
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			utils.RespondBadRequest(c, err, "The link would expire before it activates")
+			return
+		}
+
+		if errors.Is(err, services.ErrUnknownCampaign) {
+			utils.RespondBadRequest(c, err, "The workspace has no campaign template with this name")
+			return
+		}
+
 		log.Error().Err(err).Str("originalUrl", req.OriginalURL).Msg("Failed to create shortened URL")
 		utils.RespondInternalError(c, err, "Failed to create shortened URL")
 		return
@@ -99,10 +135,297 @@ func (h *ShortenHandler) Create(c *gin.Context) {
 	utils.RespondCreated(c, result, "URL shortened successfully")
 }
 
+// CreateBatch godoc
+// @Summary Create several shortened URLs
+// @Description Creates up to 500 shortened URLs in one call. Every link is validated and created on its own and gets a result with its data or a typed error, in request order. With atomic, all links are created in one transaction or none is. Answers 201 when every link was created, 207 when only some were and 422 when none was.
+// @Tags shorten
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body models.BatchShortenRequest true "Links to create"
+// @Example request
+//
+//	{
+//	  "links": [
+//	    {"originalUrl": "https://example.com/spring", "customCode": "spring"},
+//	    {"originalUrl": "https://example.com/summer", "expiresAfter": 30}
+//	  ],
+//	  "atomic": false
+//	}
+//
+// @Success 201 {object} models.APIResponse[models.BatchShortenResponse] "Every link was created"
+// @Success 207 {object} models.APIResponse[models.BatchShortenResponse] "Some links were created, see results"
+// @Example response
+//
+//	{
+//	  "success": false,
+//	  "message": "Created 1 of 2 links",
+//	  "data": {
+//	    "created": 1,
+//	    "failed": 1,
+//	    "results": [
+//	      {"index": 0, "error": {"type": "CONFLICT", "message": "The specified short code already exists"}},
+//	      {"index": 1, "data": {"shorten": {"shortCode": "Xy7aQ2"}, "shortUrl": "http://localhost:3000/Xy7aQ2"}}
+//	    ]
+//	  }
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format or more than 500 links"
+// @Failure 403 {object} models.ErrorResponse[error] "Caller is not a member of any workspace"
+// @Failure 422 {object} models.APIResponse[models.BatchShortenResponse] "No link was created, see results"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /shorten/batch [post]
+func (h *ShortenHandler) CreateBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.BatchShortenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for batch URL shortening")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	log.Info().Int("links", len(req.Links)).Bool("atomic", req.Atomic).Msg("Creating shortened URLs in batch")
+
+	results := make([]models.BatchShortenResult, len(req.Links))
+
+	// Links are validated one by one so an invalid link only fails itself
+	var valid []models.ShortenRequest
+	var validIndexes []int
+	for i := range req.Links {
+		results[i].Index = i
+		if err := binding.Validator.ValidateStruct(&req.Links[i]); err != nil {
+			results[i].Error = &models.BatchItemError{Type: models.ErrorTypeValidation, Message: err.Error()}
+			continue
+		}
+		valid = append(valid, req.Links[i])
+		validIndexes = append(validIndexes, i)
+	}
+
+	switch {
+	case req.Atomic && len(valid) < len(req.Links):
+		for _, i := range validIndexes {
+			results[i].Error = batchItemError(ctx, services.ErrBatchAborted)
+		}
+	case len(valid) > 0:
+		created, err := h.service.CreateBatch(ctx, valid, req.Atomic)
+		if err != nil {
+			if errors.Is(err, services.ErrNoWorkspace) {
+				utils.RespondForbidden(c, err, "You are not a member of any workspace")
+				return
+			}
+			log.Error().Err(err).Msg("Failed to create shortened URLs in batch")
+			utils.RespondInternalError(c, err, "Failed to create shortened URLs")
+			return
+		}
+
+		for j, result := range created {
+			i := validIndexes[j]
+			if result.Err != nil {
+				results[i].Error = batchItemError(ctx, result.Err)
+				continue
+			}
+			results[i].Data = result.Data
+		}
+	}
+
+	response := models.BatchShortenResponse{Results: results}
+	for _, result := range results {
+		if result.Error != nil {
+			response.Failed++
+		} else {
+			response.Created++
+		}
+	}
+
+	status := http.StatusMultiStatus
+	switch {
+	case response.Failed == 0:
+		status = http.StatusCreated
+	case response.Created == 0:
+		status = http.StatusUnprocessableEntity
+	}
+
+	log.Info().Int("created", response.Created).Int("failed", response.Failed).Msg("Finished batch URL shortening")
+
+	c.JSON(status, models.APIResponse[models.BatchShortenResponse]{
+		Success: response.Failed == 0,
+		Message: fmt.Sprintf("Created %d of %d links", response.Created, len(results)),
+		Data:    response,
+	})
+}
+
+// Bulk godoc
+// @Summary Delete, expire or retag many links
+// @Description Applies one action to every link of the caller selected by codes, by filter or by both (links must then match both). delete removes the links, expire ends them now (links that already expired are left alone) and retag adds addTags and removes removeTags. The filter matches links with a tag, created before a time (RFC3339 or YYYY-MM-DD), or whose original URL is on a domain or its subdomains. With dryRun the links are only listed.
+// @Tags shorten
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.BulkLinkRequest true "Action, selection and options"
+// @Example request
+//
+//	{
+//	  "action": "expire",
+//	  "filter": {"tag": "spring-sale", "createdBefore": "2024-06-01"},
+//	  "dryRun": true
+//	}
+//
+// @Success 200 {object} models.APIResponse[models.BulkLinkResult] "Links changed, or that would change on a dry run"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "message": "2 links would be expired",
+//	  "data": {
+//	    "action": "expire",
+//	    "dryRun": true,
+//	    "matched": 2,
+//	    "codes": ["abc123", "def456"]
+//	  }
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format, empty selection or retag without tags"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /shorten/bulk [post]
+func (h *ShortenHandler) Bulk(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.BulkLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for bulk link operation")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	log.Info().Str("action", string(req.Action)).Bool("dryRun", req.DryRun).Msg("Applying bulk link operation")
+
+	result, err := h.service.Bulk(ctx, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBulkRequest) {
+			log.Warn().Err(err).Msg("Invalid bulk link operation")
+			utils.RespondBadRequest(c, err, err.Error())
+			return
+		}
+		log.Error().Err(err).Str("action", string(req.Action)).Msg("Failed to apply bulk link operation")
+		utils.RespondInternalError(c, err, "Failed to apply bulk link operation")
+		return
+	}
+
+	verb := map[models.BulkAction]string{
+		models.BulkActionDelete: "deleted",
+		models.BulkActionExpire: "expired",
+		models.BulkActionRetag:  "retagged",
+	}[req.Action]
+	message := fmt.Sprintf("%d links %s", result.Matched, verb)
+	if req.DryRun {
+		message = fmt.Sprintf("%d links would be %s", result.Matched, verb)
+	}
+
+	utils.RespondOK(c, *result, message)
+}
+
+// batchItemError types the error that kept one link of a batch from being
+// created, the way Create would answer it
+func batchItemError(ctx context.Context, err error) *models.BatchItemError {
+	switch {
+	case errors.Is(err, services.ErrShortCodeExists):
+		return &models.BatchItemError{Type: models.ErrorTypeConflict, Message: "The specified short code already exists"}
+	case errors.Is(err, services.ErrShortCodeReserved):
+		return &models.BatchItemError{Type: models.ErrorTypeBadRequest, Message: "The specified short code is reserved"}
+	case errors.Is(err, services.ErrInvalidShortCode):
+		return &models.BatchItemError{Type: models.ErrorTypeBadRequest, Message: err.Error()}
+	case errors.Is(err, services.ErrInvalidSchedule):
+		return &models.BatchItemError{Type: models.ErrorTypeBadRequest, Message: "The link would expire before it activates"}
+	case errors.Is(err, services.ErrUnknownCampaign):
+		return &models.BatchItemError{Type: models.ErrorTypeBadRequest, Message: "The workspace has no campaign template with this name"}
+	case errors.Is(err, services.ErrBatchAborted):
+		return &models.BatchItemError{Type: models.ErrorTypeFailedCheck, Message: "Not created because other links in the atomic batch failed"}
+	default:
+		log := utils.LoggerFromContext(ctx)
+		log.Error().Err(err).Msg("Failed to create shortened URL in batch")
+		return &models.BatchItemError{Type: models.ErrorTypeInternalError, Message: "Failed to create shortened URL"}
+	}
+}
+
+// List godoc
+// @Summary List shortened URLs
+// @Description Returns a page of shortened URLs. Page size is capped by app.maxPageSize. Results can be filtered by creation date, expiry state, tag and a substring of the original URL, and sorted by creation date or click count. Every link of the caller's workspace is listed, whoever created it.
+// @Tags shorten
+// @Security ApiKeyAuth
+// @Produce json
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Items per page, capped by app.maxPageSize" default(20)
+// @Param q query string false "Substring of the original URL (case-insensitive)"
+// @Param createdFrom query string false "Only links created at or after, RFC3339 or YYYY-MM-DD"
+// @Param createdTo query string false "Only links created before, RFC3339 or YYYY-MM-DD"
+// @Param expired query bool false "Only expired (true) or only unexpired (false) links"
+// @Param tag query string false "Only links carrying this tag" example:"spring-sale"
+// @Param sortBy query string false "Sort key" Enums(createdAt, clickCount) default(createdAt)
+// @Param order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {object} models.APIResponse[models.PaginatedData[models.ShortenData]] "Successfully listed shortened URLs"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "data": {
+//	    "items": [
+//	      {
+//	        "shorten": {
+//	          "originalUrl": "https://example.com/some/long/path",
+//	          "shortCode": "abc123",
+//	          "clickCount": 12
+//	        },
+//	        "shortUrl": "http://localhost:3000/abc123"
+//	      }
+//	    ],
+//	    "page": 1,
+//	    "pageSize": 20,
+//	    "totalItems": 1,
+//	    "totalPages": 1
+//	  },
+//	  "message": "URLs retrieved successfully"
+//	}
+//
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid query parameters"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /shorten [get]
+func (h *ShortenHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var query models.ShortenListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.Error().Err(err).Msg("Invalid query for URL listing")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.List(ctx, query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidListQuery) {
+			log.Warn().Err(err).Msg("Invalid filter for URL listing")
+			utils.RespondBadRequest(c, err, err.Error())
+			return
+		}
+
+		log.Error().Err(err).Msg("Failed to list shortened URLs")
+		utils.RespondInternalError(c, err, "Failed to list shortened URLs")
+		return
+	}
+
+	log.Debug().Int("page", result.Page).Int64("total", result.TotalItems).Msg("Listed shortened URLs")
+
+	utils.RespondOK(c, result, "URLs retrieved successfully")
+}
+
 // Update godoc
 // @Summary Update a shortened URL
-// @Description Updates an existing shortened URL by its short code
+// @Description Updates an existing shortened URL by its short code. Only the owner or a holder of links:admin can update a link.
 // @Tags shorten
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param code path string true "Short code identifier" example:"abc123"
@@ -129,8 +452,8 @@ func (h *ShortenHandler) Create(c *gin.Context) {
 //	  "message": "URL updated successfully"
 //	}
 //
-// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
-// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format, expiresAt is not after activatesAt, or campaign is unknown"
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or owned by someone else"
 // @Example response
 //
 //	{
@@ -168,9 +491,13 @@ func (h *ShortenHandler) Update(c *gin.Context) {
 
 	result, err := h.service.Update(ctx, code, req)
 	if err != nil {
-		if err.Error() == "short URL not found" {
+		if errors.Is(err, services.ErrShortURLNotFound) {
 			log.Warn().Str("code", code).Msg("Short URL not found for update")
 			utils.RespondNotFound(c, err, "The specified short URL was not found")
+		} else if errors.Is(err, services.ErrInvalidSchedule) {
+			utils.RespondBadRequest(c, err, "The link would expire before it activates")
+		} else if errors.Is(err, services.ErrUnknownCampaign) {
+			utils.RespondBadRequest(c, err, "The workspace has no campaign template with this name")
 		} else {
 			log.Error().Err(err).Str("code", code).Msg("Failed to update shortened URL")
 			utils.RespondInternalError(c, err, "Failed to update shortened URL")
@@ -185,8 +512,9 @@ func (h *ShortenHandler) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a shortened URL
-// @Description Deletes an existing shortened URL by its short code
+// @Description Deletes an existing shortened URL by its short code. Only the owner or a holder of links:admin can delete a link.
 // @Tags shorten
+// @Security ApiKeyAuth
 // @Param code path string true "Short code identifier" example:"abc123"
 // @Success 204 "No Content - URL successfully deleted"
 // @Failure 400 {object} models.ErrorResponse[error] "Bad request - missing code parameter"
@@ -200,7 +528,7 @@ func (h *ShortenHandler) Update(c *gin.Context) {
 //	  "requestId": "c7f3305d-8c9a-4b9b-b701-3b9a1e36c1f0"
 //	}
 //
-// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found"
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or owned by someone else"
 // @Failure 500 {object} models.ErrorResponse[error] "Server error"
 // @Router /shorten/{code} [delete]
 func (h *ShortenHandler) Delete(c *gin.Context) {
@@ -218,7 +546,7 @@ func (h *ShortenHandler) Delete(c *gin.Context) {
 
 	err := h.service.Delete(ctx, code)
 	if err != nil {
-		if err.Error() == "short URL not found" {
+		if errors.Is(err, services.ErrShortURLNotFound) {
 			log.Warn().Str("code", code).Msg("Short URL not found for deletion")
 			utils.RespondNotFound(c, err, "The specified short URL was not found")
 		} else {
@@ -234,13 +562,21 @@ func (h *ShortenHandler) Delete(c *gin.Context) {
 
 // Redirect godoc
 // @Summary Redirect to original URL
-// @Description Redirects to the original URL from a short code
+// @Description Redirects to the original URL from a short code. This route is served at the root of the host (/{code}), outside the /api/v1 base path. Links with passthrough also resolve at /{code}/any/path: the trailing path is appended to the destination's path and the query string is merged into the destination's, where parameters the destination already has win. Without passthrough, extra path segments answer 404 and the query string is ignored. Visitors go to the first of the link's rules matching their device (from the User-Agent) and country (from the client IP and geoip.databasePath), else to one of its split destinations (picked by weight and kept in a cookie for 30 days), else to its original URL. The link's UTM parameters are added to the destination's query string unless it already has them. The status is the link's redirectStatus, else its workspace's defaultRedirectStatus, else redirect.defaultStatus (302 by default).
 // @Tags shorten
 // @Param code path string true "Short code identifier" example:"abc123"
+// @Param X-Link-Password header string false "Password of a protected link, for API clients"
+// @Success 301 "Moved Permanently - Redirects to the original URL"
 // @Success 302 "Found - Redirects to the original URL"
-// @Header 302 {string} Location "The URL to redirect to"
+// @Success 307 "Temporary Redirect - Redirects to the original URL"
+// @Success 308 "Permanent Redirect - Redirects to the original URL"
+// @Success 302 "Found - Redirects an expired, disabled or out of clicks link to its fallback URL"
+// @Header 301,302,307,308 {string} Location "The URL to redirect to"
 // @Failure 400 {object} models.ErrorResponse[error] "Bad request - missing code parameter"
-// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or has expired"
+// @Failure 401 {object} models.ErrorResponse[error] "Password required or wrong; browsers get an HTML password prompt instead"
+// @Failure 403 {object} models.ErrorResponse[error] "Link is not active yet, see Retry-After"
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found, expired, disabled or out of clicks; browsers get an HTML page instead"
+// @Failure 429 {object} models.ErrorResponse[error] "Too many wrong passwords, see Retry-After"
 // @Example response
 //
 //	{
@@ -253,8 +589,32 @@ func (h *ShortenHandler) Delete(c *gin.Context) {
 //	  "requestId": "c7f3305d-8c9a-4b9b-b701-3b9a1e36c1f0"
 //	}
 //
-// @Router /shorten/{code} [get]
+// @Router /{code} [get]
 func (h *ShortenHandler) Redirect(c *gin.Context) {
+	h.redirect(c, c.GetHeader(linkPasswordHeader))
+}
+
+// Unlock godoc
+// @Summary Unlock a password-protected link
+// @Description Target of the HTML password prompt. Redirects with 303 See Other when the password is correct and shows the prompt again when it is not. Visitors are locked out for redirect.passwordLockout minutes after redirect.passwordMaxAttempts wrong passwords.
+// @Tags shorten
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param code path string true "Short code identifier" example:"abc123"
+// @Param password formData string true "Link password"
+// @Success 303 "See Other - Redirects to the original URL"
+// @Header 303 {string} Location "The URL to redirect to"
+// @Failure 401 {string} string "Password prompt with an error message"
+// @Failure 404 {object} models.ErrorResponse[error] "Short URL not found or has expired"
+// @Failure 429 {string} string "Password prompt explaining the lockout"
+// @Router /{code} [post]
+func (h *ShortenHandler) Unlock(c *gin.Context) {
+	h.redirect(c, c.PostForm("password"))
+}
+
+// redirect resolves the short code in the path and redirects the visitor,
+// offering password to protected links
+func (h *ShortenHandler) redirect(c *gin.Context, password string) {
 	ctx := c.Request.Context()
 	log := utils.LoggerFromContext(ctx)
 
@@ -265,23 +625,127 @@ func (h *ShortenHandler) Redirect(c *gin.Context) {
 		return
 	}
 
+	// Reserved paths such as /api or /swagger never resolve to a short link
+	if utils.IsReservedShortCode(code) {
+		log.Debug().Str("code", code).Msg("Reserved path requested as short code")
+		utils.RespondNotFound(c, nil)
+		return
+	}
+
 	log.Info().Str("code", code).Msg("Redirecting to original URL")
 
-	url, err := h.service.GetOriginalURL(ctx, code)
+	visitor := models.Visitor{
+		Host:           c.Request.Host,
+		IP:             c.ClientIP(),
+		Referrer:       c.Request.Referer(),
+		UserAgent:      c.Request.UserAgent(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+		Password:       password,
+		DestinationID:  variantFromCookie(c, code),
+		Path:           strings.TrimPrefix(c.Param("path"), "/"),
+		Query:          c.Request.URL.RawQuery,
+	}
+
+	result, err := h.service.GetOriginalURL(ctx, code, visitor)
 	if err != nil {
+		h.respondRedirectError(c, code, err)
+		return
+	}
+
+	if result.DestinationID != 0 && result.DestinationID != visitor.DestinationID {
+		setVariantCookie(c, code, result.DestinationID)
+	}
+
+	// A submitted password form must not be replayed to the destination,
+	// which 307 and 308 would do
+	status := result.StatusCode
+	if c.Request.Method == http.MethodPost {
+		status = http.StatusSeeOther
+	}
+
+	log.Info().Str("code", code).Str("originalUrl", result.URL).Int("status", status).Msg("Successfully redirecting to original URL")
+	c.Redirect(status, result.URL)
+}
+
+// respondRedirectError answers a redirect that could not be followed.
+// Browsers get HTML pages, such as the password prompt for protected links;
+// API clients get JSON.
+func (h *ShortenHandler) respondRedirectError(c *gin.Context, code string, err error) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	var locked *services.PasswordLockedError
+	var notActive *services.NotActiveError
+	var unavailable *services.UnavailableError
+	switch {
+	case errors.As(err, &notActive):
+		log.Info().Str("code", code).Time("activatesAt", notActive.ActivatesAt).Msg("Link is not active yet")
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(notActive.ActivatesAt).Seconds()+0.5)))
+		if wantsHTML(c) {
+			renderNotActivePage(c, notActive.ActivatesAt)
+			return
+		}
+		utils.RespondForbidden(c, err, "This link is not active yet, it activates at "+notActive.ActivatesAt.UTC().Format(time.RFC3339))
+	case errors.As(err, &locked):
+		log.Warn().Str("code", code).Str("ip", c.ClientIP()).Msg("Visitor locked out of protected link")
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds()+0.5)))
+		if wantsHTML(c) {
+			renderPasswordPrompt(c, http.StatusTooManyRequests, code, "Too many wrong passwords. Please try again later.")
+			return
+		}
+		utils.RespondTooManyRequests(c, err, "Too many wrong passwords for this link, please try again later")
+	case errors.Is(err, services.ErrPasswordRequired):
+		if wantsHTML(c) {
+			renderPasswordPrompt(c, http.StatusUnauthorized, code, "")
+			return
+		}
+		utils.RespondUnauthorized(c, err, "This link is password protected, send the password in the "+linkPasswordHeader+" header")
+	case errors.Is(err, services.ErrInvalidPassword):
+		log.Info().Str("code", code).Msg("Wrong password for protected link")
+		if wantsHTML(c) {
+			renderPasswordPrompt(c, http.StatusUnauthorized, code, "Wrong password, please try again.")
+			return
+		}
+		utils.RespondUnauthorized(c, err, "The link password is wrong")
+	case errors.As(err, &unavailable):
+		message := unavailableMessage(unavailable.Reason)
+		log.Info().Str("code", code).Str("reason", unavailable.Reason.Error()).Str("fallbackUrl", unavailable.FallbackURL).Msg("Link is unavailable")
+		if unavailable.FallbackURL != "" {
+			status := http.StatusFound
+			if c.Request.Method == http.MethodPost {
+				status = http.StatusSeeOther
+			}
+			c.Header("Cache-Control", "no-store")
+			c.Redirect(status, unavailable.FallbackURL)
+			return
+		}
+		if wantsHTML(c) {
+			renderUnavailablePage(c, unavailable.FallbackPage, message)
+			return
+		}
+		utils.RespondNotFound(c, err, message)
+	default:
 		log.Warn().Err(err).Str("code", code).Msg("Failed to retrieve original URL for redirect")
 		utils.RespondNotFound(c, err, "The specified short URL was not found or has expired")
-		return
 	}
+}
 
-	log.Info().Str("code", code).Str("originalUrl", url).Msg("Successfully redirecting to original URL")
-	c.Redirect(http.StatusFound, url)
+// unavailableMessage explains to visitors why a link no longer redirects
+func unavailableMessage(reason error) string {
+	switch {
+	case errors.Is(reason, services.ErrShortURLExhausted):
+		return "This link has reached its click limit"
+	case errors.Is(reason, services.ErrShortURLDisabled):
+		return "This link has been disabled"
+	default:
+		return "This link has expired"
+	}
 }
 
 // GetByOriginalURL godoc
 // @Summary Check if a URL is already shortened
 // @Description Checks if an original URL already has a short code and optionally creates one if it doesn't exist
 // @Tags shorten
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param request body models.GetByOriginalURLRequest true "Original URL to check"
@@ -310,7 +774,7 @@ func (h *ShortenHandler) Redirect(c *gin.Context) {
 //	}
 //
 // @Success 201 {object} models.APIResponse[models.ShortenData] "Successfully created new shortened URL"
-// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format, or custom code is invalid, already exists or is reserved"
 // @Failure 404 {object} models.ErrorResponse[error] "Original URL not found and createIfNotExists is false"
 // @Example response
 //
@@ -358,6 +822,17 @@ func (h *ShortenHandler) GetByOriginalURL(c *gin.Context) {
 			}
 
 			result, err = h.service.Create(ctx, shortenReq)
+			switch {
+			case errors.Is(err, services.ErrShortCodeExists):
+				utils.RespondBadRequest(c, err, "The specified short code already exists")
+				return
+			case errors.Is(err, services.ErrShortCodeReserved):
+				utils.RespondBadRequest(c, err, "The specified short code is reserved")
+				return
+			case errors.Is(err, services.ErrInvalidShortCode):
+				utils.RespondBadRequest(c, err, err.Error())
+				return
+			}
 			if err != nil {
 				log.Error().Err(err).Str("originalUrl", req.OriginalURL).Msg("Failed to create shortened URL")
 				utils.RespondInternalError(c, err, "Failed to create shortened URL")
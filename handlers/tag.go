@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service services.TagService
+}
+
+func NewTagHandler(service services.TagService) *TagHandler {
+	return &TagHandler{
+		service: service,
+	}
+}
+
+// List godoc
+// @Summary List tags with their click counts
+// @Description Lists the tags of the caller's workspace with the number of links carrying each tag and the sum of their clicks, busiest tag first. Filter links by tag with GET /shorten?tag=.
+// @Tags tags
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[[]models.TagStats] "Tags with aggregate counts"
+// @Example response
+//
+//	{
+//	  "success": true,
+//	  "message": "Tags retrieved successfully",
+//	  "data": [
+//	    {"name": "spring-sale", "links": 12, "clicks": 3400},
+//	    {"name": "newsletter", "links": 4, "clicks": 210}
+//	  ]
+//	}
+//
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /tags [get]
+func (h *TagHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	stats, err := h.service.Stats(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list tags")
+		utils.RespondInternalError(c, err, "Failed to list tags")
+		return
+	}
+
+	// Answer an empty list rather than null when no link is tagged
+	if stats == nil {
+		stats = []models.TagStats{}
+	}
+
+	utils.RespondOK(c, stats, "Tags retrieved successfully")
+}
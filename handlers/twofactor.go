@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	service services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service: service,
+	}
+}
+
+// Setup godoc
+// @Summary Start two-factor enrollment
+// @Description Generates a TOTP secret for the current user and returns it as a secret, an otpauth:// URI and a QR code (PNG data URI). Two-factor authentication is not active until confirmed via /auth/2fa/enable.
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[models.TwoFactorSetupData] "Enrollment started"
+// @Failure 400 {object} models.ErrorResponse[error] "Not a user session"
+// @Failure 409 {object} models.ErrorResponse[error] "Two-factor authentication already enabled"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	result, err := h.service.Setup(ctx, utils.PrincipalFromContext(ctx))
+	if err != nil {
+		h.respondTwoFactorError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	utils.RespondOK(c, result, "Scan the QR code and confirm with a code to enable two-factor authentication")
+}
+
+// Enable godoc
+// @Summary Confirm two-factor enrollment
+// @Description Confirms enrollment with a code from the authenticator app and returns ten single-use recovery codes. They are only shown once. If auth.enable2FA is on, log in again with a code to obtain unrestricted tokens.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} models.APIResponse[models.RecoveryCodesData] "Two-factor authentication enabled"
+// @Failure 400 {object} models.ErrorResponse[error] "Enrollment not started or not a user session"
+// @Failure 401 {object} models.ErrorResponse[error] "Invalid code"
+// @Failure 409 {object} models.ErrorResponse[error] "Two-factor authentication already enabled"
+// @Failure 429 {object} models.ErrorResponse[error] "Too many invalid codes, see Retry-After"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for two-factor enable")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	principal := utils.PrincipalFromContext(ctx)
+	result, err := h.service.Enable(ctx, principal, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	log.Info().Str("principal", principal.Name).Msg("Two-factor authentication enabled")
+
+	utils.RespondOK(c, result, "Two-factor authentication enabled")
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turns off two-factor authentication for the current user after verifying a TOTP or recovery code. Not allowed while auth.enable2FA is on.
+// @Tags auth
+// @Accept json
+// @Security ApiKeyAuth
+// @Param request body models.TwoFactorDisableRequest true "TOTP code or recovery code"
+// @Success 204 "No Content - two-factor authentication disabled"
+// @Failure 400 {object} models.ErrorResponse[error] "Not enabled or not a user session"
+// @Failure 401 {object} models.ErrorResponse[error] "Invalid or missing code"
+// @Failure 403 {object} models.ErrorResponse[error] "Two-factor authentication is enforced"
+// @Failure 429 {object} models.ErrorResponse[error] "Too many invalid codes, see Retry-After"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for two-factor disable")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	principal := utils.PrincipalFromContext(ctx)
+	if err := h.service.Disable(ctx, principal, req); err != nil {
+		h.respondTwoFactorError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	log.Info().Str("principal", principal.Name).Msg("Two-factor authentication disabled")
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replaces all recovery codes of the current user with ten new ones
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} models.APIResponse[models.RecoveryCodesData] "New recovery codes"
+// @Failure 400 {object} models.ErrorResponse[error] "Not enabled or not a user session"
+// @Failure 401 {object} models.ErrorResponse[error] "Invalid code"
+// @Failure 429 {object} models.ErrorResponse[error] "Too many invalid codes, see Retry-After"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for recovery code regeneration")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.RegenerateRecoveryCodes(ctx, utils.PrincipalFromContext(ctx), req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	utils.RespondOK(c, result, "Recovery codes regenerated")
+}
+
+// respondTwoFactorError maps enrollment failures onto HTTP responses
+func (h *TwoFactorHandler) respondTwoFactorError(c *gin.Context, err error, message string) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	var locked *services.TwoFactorLockedError
+	switch {
+	case errors.Is(err, services.ErrNotUserSession):
+		utils.RespondBadRequest(c, err, "Two-factor authentication is only available to user sessions")
+	case errors.Is(err, services.ErrTwoFactorNotSetUp):
+		utils.RespondBadRequest(c, err, "Two-factor authentication is not set up")
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		utils.RespondConflict(c, err, "Two-factor authentication is already enabled")
+	case errors.Is(err, services.ErrTwoFactorEnforced):
+		utils.RespondForbidden(c, err, "Two-factor authentication is required by the administrator")
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		utils.RespondUnauthorized(c, err, "A two-factor code or recovery code is required")
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		utils.RespondUnauthorized(c, err, "The two-factor code is invalid or was already used")
+	case errors.As(err, &locked):
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds()+0.5)))
+		utils.RespondTooManyRequests(c, err, "Too many invalid two-factor codes, please try again later")
+	default:
+		log.Error().Err(err).Msg(message)
+		utils.RespondInternalError(c, err, message)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	service services.UserService
+}
+
+func NewUserHandler(service services.UserService) *UserHandler {
+	return &UserHandler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create a local user
+// @Description Creates a user that can log in via POST /auth/login. Passwords are stored as bcrypt hashes. The user becomes a member of the caller's workspace. Only callers holding config:admin or workspaces:admin may create admins.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CreateUserRequest true "New user"
+// @Example request
+//
+//	{
+//	  "username": "jane",
+//	  "email": "jane@example.com",
+//	  "password": "correct-horse-battery",
+//	  "isAdmin": false
+//	}
+//
+// @Success 201 {object} models.APIResponse[models.User] "Successfully created user"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing users:admin scope, or config:admin or workspaces:admin for an admin"
+// @Failure 409 {object} models.ErrorResponse[error] "Username or email already in use"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /users [post]
+func (h *UserHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for user creation")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	user, err := h.service.Create(ctx, req)
+	if err != nil {
+		if errors.Is(err, services.ErrUsernameTaken) {
+			utils.RespondConflict(c, err, "The username or email is already in use")
+			return
+		}
+		if errors.Is(err, services.ErrAdminDenied) {
+			utils.RespondForbidden(c, err, "Creating an admin requires the config:admin or workspaces:admin scope")
+			return
+		}
+
+		log.Error().Err(err).Str("username", req.Username).Msg("Failed to create user")
+		utils.RespondInternalError(c, err, "Failed to create user")
+		return
+	}
+
+	log.Info().Uint64("userId", user.ID).Str("username", user.Username).Msg("Created user")
+
+	utils.RespondCreated(c, user, "User created successfully")
+}
+
+// List godoc
+// @Summary List local users
+// @Tags users
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[[]models.User] "Successfully listed users"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing users:admin scope"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /users [get]
+func (h *UserHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	users, err := h.service.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list users")
+		utils.RespondInternalError(c, err, "Failed to list users")
+		return
+	}
+
+	utils.RespondOK(c, users, "Users retrieved successfully")
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// variantCookiePrefix names the cookie that keeps a visitor on the same
+// destination of a split link. The link's code completes the name.
+const variantCookiePrefix = "portus_variant_"
+
+// variantCookieMaxAge is how long a visitor keeps their split destination
+const variantCookieMaxAge = 30 * 24 * 60 * 60
+
+// variantFromCookie returns the split destination the visitor was assigned
+// to on an earlier visit to the link with code, or zero
+func variantFromCookie(c *gin.Context, code string) uint64 {
+	value, err := c.Cookie(variantCookiePrefix + code)
+	if err != nil {
+		return 0
+	}
+
+	destinationID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return destinationID
+}
+
+// setVariantCookie remembers the split destination of the link with code.
+// The cookie is scoped to the link's path, so links do not see each other's.
+func setVariantCookie(c *gin.Context, code string, destinationID uint64) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(variantCookiePrefix+code, strconv.FormatUint(destinationID, 10),
+		variantCookieMaxAge, "/"+code, "", c.Request.TLS != nil, true)
+}
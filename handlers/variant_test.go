@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVariantFromCookie(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		want   uint64
+	}{
+		{name: "no cookie", want: 0},
+		{name: "assigned destination", cookie: &http.Cookie{Name: "portus_variant_abc", Value: "42"}, want: 42},
+		{name: "cookie of another link", cookie: &http.Cookie{Name: "portus_variant_xyz", Value: "42"}, want: 0},
+		{name: "not a number", cookie: &http.Cookie{Name: "portus_variant_abc", Value: "abc"}, want: 0},
+		{name: "negative", cookie: &http.Cookie{Name: "portus_variant_abc", Value: "-1"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/abc", nil)
+			if tt.cookie != nil {
+				c.Request.AddCookie(tt.cookie)
+			}
+
+			if got := variantFromCookie(c, "abc"); got != tt.want {
+				t.Errorf("variantFromCookie() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetVariantCookie(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/abc", nil)
+
+	setVariantCookie(c, "abc", 7)
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Name != "portus_variant_abc" || cookie.Value != "7" || cookie.Path != "/abc" || !cookie.HttpOnly {
+		t.Errorf("cookie = %+v, want portus_variant_abc=7 on /abc, HttpOnly", cookie)
+	}
+}
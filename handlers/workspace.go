@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+	service services.WorkspaceService
+}
+
+func NewWorkspaceHandler(service services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create a workspace
+// @Description Creates a workspace. A user creating it becomes its owner. Links of a workspace with a domain are served on that domain.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.WorkspaceRequest true "Workspace name, slug, optional domain and settings"
+// @Example request
+//
+//	{
+//	  "name": "Acme Shoes",
+//	  "slug": "acme-shoes",
+//	  "domain": "go.acme.com",
+//	  "settings": {
+//	    "defaultExpiresAfter": 30
+//	  }
+//	}
+//
+// @Success 201 {object} models.APIResponse[models.Workspace] "Successfully created workspace"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format or slug"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin scope"
+// @Failure 409 {object} models.ErrorResponse[error] "Slug or domain already in use"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	var req models.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for workspace creation")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	workspace, err := h.service.Create(ctx, req)
+	if err != nil {
+		h.respondWorkspaceError(c, err, "Failed to create workspace")
+		return
+	}
+
+	log.Info().Uint64("workspaceId", workspace.ID).Str("slug", workspace.Slug).Msg("Created workspace")
+
+	utils.RespondCreated(c, workspace, "Workspace created successfully")
+}
+
+// List godoc
+// @Summary List workspaces
+// @Description Lists the workspaces the caller belongs to. Holders of workspaces:admin see every workspace.
+// @Tags workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.APIResponse[[]models.Workspace] "Successfully listed workspaces"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	workspaces, err := h.service.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list workspaces")
+		utils.RespondInternalError(c, err, "Failed to list workspaces")
+		return
+	}
+
+	utils.RespondOK(c, workspaces, "Workspaces retrieved successfully")
+}
+
+// Get godoc
+// @Summary Get a workspace
+// @Tags workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Success 200 {object} models.APIResponse[models.Workspace] "Workspace found"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid workspace ID"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace not found"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id} [get]
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.service.Get(ctx, id)
+	if err != nil {
+		h.respondWorkspaceError(c, err, "Failed to get workspace")
+		return
+	}
+
+	utils.RespondOK(c, workspace, "Workspace retrieved successfully")
+}
+
+// Update godoc
+// @Summary Update a workspace
+// @Description Replaces the name, slug, domain and settings of a workspace. Changing the domain moves the workspace's links to it, unless another workspace already uses one of their codes there. Requires workspaces:admin, or members:admin (owner role) in that workspace; only workspaces:admin may change the domain.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Param request body models.WorkspaceRequest true "Workspace name, slug, optional domain and settings"
+// @Success 200 {object} models.APIResponse[models.Workspace] "Successfully updated workspace"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format, slug or domain"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin or members:admin scope, or workspaces:admin for a domain change"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace not found"
+// @Failure 409 {object} models.ErrorResponse[error] "Slug, domain or link codes on the domain already in use"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id} [put]
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for workspace update")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	workspace, err := h.service.Update(ctx, id, req)
+	if err != nil {
+		h.respondWorkspaceError(c, err, "Failed to update workspace")
+		return
+	}
+
+	utils.RespondOK(c, workspace, "Workspace updated successfully")
+}
+
+// ListMembers godoc
+// @Summary List workspace members
+// @Description Lists the members of a workspace with their roles
+// @Tags workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Success 200 {object} models.APIResponse[[]models.WorkspaceMemberData] "Successfully listed members"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid workspace ID"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin or members:admin scope"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace not found"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id}/members [get]
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(ctx, id)
+	if err != nil {
+		h.respondWorkspaceError(c, err, "Failed to list workspace members")
+		return
+	}
+
+	utils.RespondOK(c, members, "Members retrieved successfully")
+}
+
+// AddMember godoc
+// @Summary Add a workspace member
+// @Description Adds a user to a workspace with a role: viewer, editor (default), admin or owner. Existing members keep their role; use PUT to change it.
+// @Tags workspaces
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Param request body models.AddWorkspaceMemberRequest true "User to add"
+// @Success 204 "No Content - user is a member"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin or members:admin scope"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace or user not found"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for adding a workspace member")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	if err := h.service.AddMember(ctx, id, req); err != nil {
+		h.respondWorkspaceError(c, err, "Failed to add workspace member")
+		return
+	}
+
+	log.Info().Uint64("workspaceId", id).Uint64("userId", req.UserID).Msg("Added workspace member")
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Description Changes the role of a workspace member. The last owner cannot be demoted.
+// @Tags workspaces
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Param userId path int true "User ID" example:"2"
+// @Param request body models.UpdateWorkspaceMemberRequest true "New role"
+// @Success 204 "No Content - role changed"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid request format"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin or members:admin scope"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace not found or user is not a member"
+// @Failure 409 {object} models.ErrorResponse[error] "The user is the last owner"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id}/members/{userId} [put]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		utils.RespondBadRequest(c, err, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Error().Err(err).Msg("Invalid request format for workspace member update")
+		utils.RespondValidationError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateMember(ctx, id, userID, req); err != nil {
+		h.respondWorkspaceError(c, err, "Failed to update workspace member")
+		return
+	}
+
+	log.Info().Uint64("workspaceId", id).Uint64("userId", userID).Str("role", string(req.Role)).Msg("Changed workspace member role")
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveMember godoc
+// @Summary Remove a workspace member
+// @Tags workspaces
+// @Security ApiKeyAuth
+// @Param id path int true "Workspace ID" example:"1"
+// @Param userId path int true "User ID" example:"2"
+// @Success 204 "No Content - user removed"
+// @Failure 400 {object} models.ErrorResponse[error] "Invalid ID"
+// @Failure 403 {object} models.ErrorResponse[error] "Missing workspaces:admin or members:admin scope"
+// @Failure 404 {object} models.ErrorResponse[error] "Workspace not found or user is not a member"
+// @Failure 409 {object} models.ErrorResponse[error] "The user is the last owner"
+// @Failure 500 {object} models.ErrorResponse[error] "Server error"
+// @Router /workspaces/{id}/members/{userId} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := utils.LoggerFromContext(ctx)
+
+	id, ok := workspaceIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		utils.RespondBadRequest(c, err, "Invalid user ID")
+		return
+	}
+
+	if err := h.service.RemoveMember(ctx, id, userID); err != nil {
+		h.respondWorkspaceError(c, err, "Failed to remove workspace member")
+		return
+	}
+
+	log.Info().Uint64("workspaceId", id).Uint64("userId", userID).Msg("Removed workspace member")
+	c.Status(http.StatusNoContent)
+}
+
+// workspaceIDParam parses the :id path parameter, responding with 400 if it
+// is not a number
+func workspaceIDParam(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondBadRequest(c, err, "Invalid workspace ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWorkspaceError maps workspace failures onto HTTP responses
+func (h *WorkspaceHandler) respondWorkspaceError(c *gin.Context, err error, message string) {
+	log := utils.LoggerFromContext(c.Request.Context())
+
+	switch {
+	case errors.Is(err, services.ErrWorkspaceNotFound):
+		utils.RespondNotFound(c, err, "The specified workspace was not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.RespondNotFound(c, err, "The specified user was not found")
+	case errors.Is(err, services.ErrInvalidWorkspace):
+		utils.RespondBadRequest(c, err, err.Error())
+	case errors.Is(err, services.ErrDomainChangeDenied):
+		utils.RespondForbidden(c, err, err.Error())
+	case errors.Is(err, services.ErrWorkspaceSlugTaken):
+		utils.RespondConflict(c, err, "The workspace slug is already in use")
+	case errors.Is(err, services.ErrWorkspaceDomainTaken):
+		utils.RespondConflict(c, err, "The domain is already used by another workspace")
+	case errors.Is(err, services.ErrDomainCodesTaken):
+		utils.RespondConflict(c, err, err.Error())
+	case errors.Is(err, services.ErrLastWorkspaceOwner):
+		utils.RespondConflict(c, err, "A workspace must keep at least one owner")
+	default:
+		log.Error().Err(err).Msg(message)
+		utils.RespondInternalError(c, err, message)
+	}
+}
@@ -30,6 +30,11 @@ import (
 //	@license.name	Apache 2.0
 //	@license.url	http://www.apache.org/licenses/LICENSE-2.0.html
 
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key created via POST /keys. API keys and user access tokens from POST /auth/login may also be sent as "Authorization: Bearer <token>".
+
 // @host		localhost:8080
 // @BasePath	/api/v1
 // @schemes	http
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"portus/services"
+	"portus/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyPrefix tells API keys apart from JWTs in a bearer Authorization header
+const apiKeyPrefix = "pk_"
+
+// workspaceHeader lets user sessions choose the workspace a request operates on
+const workspaceHeader = "X-Workspace-ID"
+
+// Authenticate rejects requests without a valid credential and stores the
+// resolved principal in the request context. It accepts an API key in the
+// X-API-Key header, or an API key or user access token as a bearer token.
+// The principal's workspace is taken from the X-Workspace-ID header, which
+// API keys may only set to their own workspace.
+func Authenticate(apiKeys services.APIKeyService, auth services.AuthService, workspaces services.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		log := utils.LoggerFromContext(ctx)
+
+		principal, err := resolvePrincipal(ctx, c, apiKeys, auth)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) || errors.Is(err, services.ErrInvalidToken) {
+				log.Warn().Err(err).Msg("Rejected invalid credentials")
+				utils.RespondUnauthorized(c, err, "The provided credentials are invalid, expired or revoked")
+			} else {
+				log.Error().Err(err).Msg("Failed to verify credentials")
+				utils.RespondInternalError(c, err, "Failed to verify credentials")
+			}
+			c.Abort()
+			return
+		}
+
+		if principal == nil {
+			utils.RespondUnauthorized(c, nil, "An API key or access token is required")
+			c.Abort()
+			return
+		}
+
+		var requested uint64
+		if header := c.GetHeader(workspaceHeader); header != "" {
+			requested, err = strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				utils.RespondBadRequest(c, err, "The "+workspaceHeader+" header must be a workspace ID")
+				c.Abort()
+				return
+			}
+		}
+
+		if err := workspaces.Resolve(ctx, principal, requested); err != nil {
+			if errors.Is(err, services.ErrNotWorkspaceMember) {
+				utils.RespondForbidden(c, err, "You are not a member of the requested workspace")
+			} else {
+				log.Error().Err(err).Msg("Failed to resolve workspace")
+				utils.RespondInternalError(c, err, "Failed to resolve workspace")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(utils.WithPrincipal(ctx, principal))
+		c.Next()
+	}
+}
+
+// resolvePrincipal returns nil without an error when no credential was sent
+func resolvePrincipal(ctx context.Context, c *gin.Context, apiKeys services.APIKeyService, auth services.AuthService) (*models.Principal, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return apiKeys.Authenticate(ctx, key)
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+
+	token = strings.TrimSpace(token)
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		return apiKeys.Authenticate(ctx, token)
+	}
+	return auth.Authenticate(ctx, token)
+}
+
+// RequireScope rejects requests whose principal was not granted scope. It
+// must run after Authenticate.
+func RequireScope(scope models.Scope) gin.HandlerFunc {
+	return RequireAnyScope(scope)
+}
+
+// RequireAnyScope rejects requests whose principal holds none of scopes.
+// User sessions hold the scopes of their role in the current workspace, see
+// models.Role. It must run after Authenticate.
+func RequireAnyScope(scopes ...models.Scope) gin.HandlerFunc {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	message := "This operation requires the " + strings.Join(names, " or ") + " scope"
+
+	return func(c *gin.Context) {
+		principal := utils.PrincipalFromContext(c.Request.Context())
+		if principal != nil && principal.TwoFactorSetupRequired {
+			utils.RespondForbidden(c, nil, "Two-factor authentication must be enabled before using this API")
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if principal.HasScope(scope) {
+				c.Next()
+				return
+			}
+		}
+
+		utils.RespondForbidden(c, nil, message)
+		c.Abort()
+	}
+}
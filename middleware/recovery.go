@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery answers a panicking request with a 500 like gin's own recovery,
+// except for http.ErrAbortHandler: that panic is passed on so net/http
+// aborts the connection, and a client of a response that is already under
+// way sees it fail instead of end early.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, err any) {
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+// Click records a single resolution of a short link
+type Click struct {
+	ID             uint64    `json:"id" gorm:"primaryKey" example:"1"`
+	ShortenID      uint64    `json:"shortenId" gorm:"index" example:"1"`
+	ShortCode      string    `json:"shortCode" gorm:"index" example:"abc123"`
+	Timestamp      time.Time `json:"timestamp" gorm:"index"`
+	Referrer       string    `json:"referrer" example:"https://news.ycombinator.com/"`
+	UserAgent      string    `json:"userAgent" example:"Mozilla/5.0"`
+	IPHash         string    `json:"ipHash" gorm:"index" example:"9f86d081884c7d65"`
+	AcceptLanguage string    `json:"acceptLanguage" example:"en-US,en;q=0.9"`
+	// DestinationID is the split destination the visitor was sent to, zero
+	// for links without destinations
+	DestinationID uint64 `json:"destinationId,omitempty" gorm:"not null;default:0" example:"1"`
+}
+
+// Visitor describes the request that resolved a short link
+type Visitor struct {
+	// Host is the Host header, used to pick the workspace domain
+	Host           string
+	IP             string
+	Referrer       string
+	UserAgent      string
+	AcceptLanguage string
+	// Password is the link password offered by the visitor, if any. It is
+	// never recorded.
+	Password string
+	// DestinationID is the split destination the visitor was assigned to on
+	// an earlier visit, zero if none
+	DestinationID uint64
+	// Path is what follows the code in the short URL, without the leading
+	// slash, and Query its raw query string. Only links with passthrough
+	// use them.
+	Path  string
+	Query string
+}
+
+// ReferrerCount is the number of clicks that arrived from a single referrer
+type ReferrerCount struct {
+	Referrer string `json:"referrer" example:"https://news.ycombinator.com/"`
+	Clicks   int64  `json:"clicks" example:"42"`
+}
+
+// ShortenStats summarises the click activity of a short link
+type ShortenStats struct {
+	ShortCode    string          `json:"shortCode" example:"abc123"`
+	Clicks       uint64          `json:"clicks" example:"1000"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	LastAccessed *time.Time      `json:"lastAccessed,omitempty"`
+	TopReferrers []ReferrerCount `json:"topReferrers"`
+	// Variants breaks the clicks of a split link down by destination
+	Variants []VariantStats `json:"variants,omitempty"`
+}
+
+// RollupInterval is the width of a click rollup bucket
+type RollupInterval string
+
+const (
+	RollupIntervalHour RollupInterval = "hour"
+	RollupIntervalDay  RollupInterval = "day"
+)
+
+// Duration returns the length of a single bucket
+func (i RollupInterval) Duration() time.Duration {
+	if i == RollupIntervalDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// ClickRollup holds the number of clicks a short link received in one bucket.
+// Buckets are aligned to UTC hour and day boundaries.
+type ClickRollup struct {
+	ID          uint64         `json:"-" gorm:"primaryKey"`
+	ShortenID   uint64         `json:"shortenId" gorm:"uniqueIndex:idx_click_rollups_bucket" example:"1"`
+	ShortCode   string         `json:"shortCode" gorm:"index" example:"abc123"`
+	Interval    RollupInterval `json:"interval" gorm:"column:bucket_interval;type:varchar(8);uniqueIndex:idx_click_rollups_bucket" example:"day"`
+	BucketStart time.Time      `json:"bucketStart" gorm:"uniqueIndex:idx_click_rollups_bucket"`
+	Clicks      int64          `json:"clicks" example:"42"`
+}
+
+// TimeseriesQuery holds the query parameters of the timeseries endpoint
+type TimeseriesQuery struct {
+	Interval string `form:"interval" binding:"omitempty,oneof=hour day" example:"day"`
+	From     string `form:"from" example:"2023-01-01T00:00:00Z"`
+	To       string `form:"to" example:"2023-02-01T00:00:00Z"`
+}
+
+// TimeseriesPoint is the click count of a single bucket
+type TimeseriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Clicks    int64     `json:"clicks" example:"42"`
+}
+
+// ShortenTimeseries is the bucketed click history of a short link
+type ShortenTimeseries struct {
+	ShortCode string            `json:"shortCode" example:"abc123"`
+	Interval  RollupInterval    `json:"interval" example:"day"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Points    []TimeseriesPoint `json:"points"`
+}
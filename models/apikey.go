@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Scope grants access to a group of management endpoints
+type Scope string
+
+const (
+	ScopeLinksRead     Scope = "links:read"
+	ScopeLinksWrite    Scope = "links:write"
+	ScopeAnalyticsRead Scope = "analytics:read"
+	ScopeConfigAdmin   Scope = "config:admin"
+	ScopeKeysAdmin     Scope = "keys:admin"
+	ScopeUsersAdmin    Scope = "users:admin"
+
+	// ScopeLinksAdmin lifts owner isolation: its holder can read and modify
+	// links created by any user or API key.
+	ScopeLinksAdmin Scope = "links:admin"
+
+	// ScopeWorkspacesAdmin allows creating workspaces, managing their members
+	// and operating on any workspace
+	ScopeWorkspacesAdmin Scope = "workspaces:admin"
+
+	// ScopeMembersAdmin allows managing the members, roles and settings of
+	// the current workspace
+	ScopeMembersAdmin Scope = "members:admin"
+)
+
+// AllScopes lists every scope that can be granted
+var AllScopes = []Scope{
+	ScopeLinksRead,
+	ScopeLinksWrite,
+	ScopeAnalyticsRead,
+	ScopeConfigAdmin,
+	ScopeKeysAdmin,
+	ScopeUsersAdmin,
+	ScopeLinksAdmin,
+	ScopeWorkspacesAdmin,
+	ScopeMembersAdmin,
+}
+
+// IsValid reports whether s is a known scope
+func (s Scope) IsValid() bool {
+	for _, scope := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a credential for the management API. Only a hash of the key is
+// stored; the plaintext is returned once, when the key is created.
+type APIKey struct {
+	ID   uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	Name string `json:"name" example:"CI pipeline"`
+	// WorkspaceID is the workspace the key was created in and operates on
+	WorkspaceID uint64     `json:"workspaceId" gorm:"not null;default:0;index" example:"1"`
+	Prefix      string     `json:"prefix" example:"pk_Xb3k9QaZ"`
+	KeyHash     string     `json:"-" gorm:"uniqueIndex"`
+	Scopes      []Scope    `json:"scopes" gorm:"serializer:json" example:"links:read,links:write"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the key can still be used to authenticate
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || k.ExpiresAt.After(time.Now())
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name         string  `json:"name" binding:"required" example:"CI pipeline"`
+	Scopes       []Scope `json:"scopes" binding:"required,min=1" example:"links:read,links:write"`
+	ExpiresAfter int     `json:"expiresAfter,omitempty" example:"90"` // In days
+}
+
+// APIKeyData is returned when a key is created and carries the plaintext key
+type APIKeyData struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key" example:"pk_Xb3k9QaZ7fLr2mN0pQs4tUv6wXy8zA1b"`
+}
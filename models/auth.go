@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// Principal identifies the authenticated caller of a request. Exactly one of
+// APIKeyID and UserID is set.
+type Principal struct {
+	APIKeyID *uint64 `json:"apiKeyId,omitempty"`
+	UserID   *uint64 `json:"userId,omitempty"`
+	Name     string  `json:"name"`
+	Scopes   []Scope `json:"scopes"`
+
+	// WorkspaceID is the workspace the request operates on. API keys are
+	// bound to one workspace; user sessions pick one per request.
+	WorkspaceID uint64 `json:"workspaceId"`
+	// Role is the user's role in that workspace, empty for API keys
+	Role Role `json:"role,omitempty"`
+
+	// TwoFactorSetupRequired is set when auth.enable2FA is on and the session
+	// was not established with a second factor. Such sessions hold no scopes
+	// and may only enroll in two-factor authentication.
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired,omitempty"`
+
+	// TokenID and TokenExpiresAt describe the access token of a user session
+	TokenID        string    `json:"-"`
+	TokenExpiresAt time.Time `json:"-"`
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshToken is a long-lived session credential exchanged for access tokens.
+// Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uint64 `gorm:"primaryKey"`
+	UserID    uint64 `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+
+	// TwoFactor records whether the session was established with a second factor
+	TwoFactor bool
+}
+
+// RevokedToken blocks an access token before it expires, e.g. after logout.
+// Rows can be dropped once ExpiresAt has passed.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// LoginRequest represents the credentials of a local user
+type LoginRequest struct {
+	Username string `json:"username" binding:"required" example:"jane"`
+	Password string `json:"password" binding:"required" example:"correct-horse-battery"`
+
+	// One of these is required when the user has two-factor authentication enabled
+	TOTPCode     string `json:"totpCode,omitempty" example:"123456"`
+	RecoveryCode string `json:"recoveryCode,omitempty" example:"ABCD-EFGH-IJKL-MNOP"`
+}
+
+// RefreshRequest exchanges a refresh token for a new token pair
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required" example:"rt_3q2+7w..."`
+}
+
+// LogoutRequest ends the current session and optionally every other one
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken,omitempty" example:"rt_3q2+7w..."`
+	AllSessions  bool   `json:"allSessions,omitempty" example:"false"`
+}
+
+// TokenData is returned by login and refresh
+type TokenData struct {
+	AccessToken  string    `json:"accessToken" example:"eyJhbGciOiJIUzI1NiIs..."`
+	RefreshToken string    `json:"refreshToken" example:"rt_3q2+7w..."`
+	TokenType    string    `json:"tokenType" example:"Bearer"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	User         *User     `json:"user"`
+
+	// TwoFactorSetupRequired means the tokens are only good for enrolling in
+	// two-factor authentication; log in again with a code afterwards
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired,omitempty"`
+}
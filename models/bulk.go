@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// BulkAction is what a bulk operation does to the links it selects
+type BulkAction string
+
+const (
+	BulkActionDelete BulkAction = "delete"
+	// BulkActionExpire expires links now. Links that already expired keep
+	// their expiry and are not selected.
+	BulkActionExpire BulkAction = "expire"
+	BulkActionRetag  BulkAction = "retag"
+)
+
+// BulkLinkRequest represents a delete, expire or retag operation on many
+// links. Links are selected by codes, by filter or by both, in which case
+// they must match both.
+type BulkLinkRequest struct {
+	Action BulkAction      `json:"action" binding:"required,oneof=delete expire retag" example:"expire"`
+	Codes  []string        `json:"codes,omitempty" binding:"omitempty,max=1000,dive,required" example:"abc123,def456"`
+	Filter *BulkLinkFilter `json:"filter,omitempty"`
+	// AddTags and RemoveTags are applied by retag
+	AddTags    []string `json:"addTags,omitempty" binding:"omitempty,max=20,dive,min=1,max=64" example:"archived"`
+	RemoveTags []string `json:"removeTags,omitempty" binding:"omitempty,max=20,dive,min=1,max=64" example:"spring-sale"`
+	// DryRun reports the links the operation would change without changing them
+	DryRun bool `json:"dryRun,omitempty" example:"true"`
+}
+
+// BulkLinkFilter selects links by their attributes
+type BulkLinkFilter struct {
+	Tag string `json:"tag,omitempty" binding:"omitempty,max=64" example:"spring-sale"`
+	// CreatedBefore is RFC3339 or YYYY-MM-DD
+	CreatedBefore string `json:"createdBefore,omitempty" example:"2024-01-01"`
+	// Domain matches links whose original URL is on this host or one of
+	// its subdomains
+	Domain string `json:"domain,omitempty" binding:"omitempty,hostname" example:"example.com"`
+}
+
+// BulkLinkResult lists the links a bulk operation changed, or would change
+// in a dry run
+type BulkLinkResult struct {
+	Action  BulkAction `json:"action" example:"expire"`
+	DryRun  bool       `json:"dryRun" example:"true"`
+	Matched int        `json:"matched" example:"2"`
+	Codes   []string   `json:"codes" example:"abc123,def456"`
+}
+
+// LinkSelection is the validated form of a bulk selection used by the
+// repository
+type LinkSelection struct {
+	Scope         LinkScope
+	Codes         []string
+	Tag           string
+	CreatedBefore *time.Time
+	Domain        string
+	// Unexpired leaves out links that have already expired
+	Unexpired bool
+}
@@ -27,28 +27,54 @@ type Configuration struct {
 
 	// HTTP contains HTTP server configuration
 	HTTP struct {
-		Port             string `json:"port" mapstructure:"port" example:"8080" binding:"required"`
-		ReadTimeout      int    `json:"readTimeout" mapstructure:"readTimeout" example:"30" binding:"required,min=1"`
-		WriteTimeout     int    `json:"writeTimeout" mapstructure:"writeTimeout" example:"30" binding:"required,min=1"`
-		IdleTimeout      int    `json:"idleTimeout" mapstructure:"idleTimeout" example:"60" binding:"required,min=1"`
-		EnableSSL        bool   `json:"enableSSL" mapstructure:"enableSSL" example:"false"`
-		SSLCert          string `json:"sslCert" mapstructure:"sslCert" example:"/path/to/cert.pem"`
-		SSLKey           string `json:"sslKey" mapstructure:"sslKey" example:"/path/to/key.pem"`
-		ProxyEnabled     bool   `json:"proxyEnabled" mapstructure:"proxyEnabled" example:"false"`
-		ProxyURL         string `json:"proxyURL" mapstructure:"proxyURL" example:"http://proxy:8080"`
-		RateLimitEnabled bool   `json:"rateLimitEnabled" mapstructure:"rateLimitEnabled" example:"true"`
-		RequestsPerMin   int    `json:"requestsPerMin" mapstructure:"requestsPerMin" example:"100" binding:"min=0"`
+		Port             string   `json:"port" mapstructure:"port" example:"8080" binding:"required"`
+		ReadTimeout      int      `json:"readTimeout" mapstructure:"readTimeout" example:"30" binding:"required,min=1"`
+		WriteTimeout     int      `json:"writeTimeout" mapstructure:"writeTimeout" example:"30" binding:"required,min=1"`
+		IdleTimeout      int      `json:"idleTimeout" mapstructure:"idleTimeout" example:"60" binding:"required,min=1"`
+		EnableSSL        bool     `json:"enableSSL" mapstructure:"enableSSL" example:"false"`
+		SSLCert          string   `json:"sslCert" mapstructure:"sslCert" example:"/path/to/cert.pem"`
+		SSLKey           string   `json:"sslKey" mapstructure:"sslKey" example:"/path/to/key.pem"`
+		ProxyEnabled     bool     `json:"proxyEnabled" mapstructure:"proxyEnabled" example:"false"`
+		ProxyURL         string   `json:"proxyURL" mapstructure:"proxyURL" example:"http://proxy:8080"`
+		TrustedProxies   []string `json:"trustedProxies" mapstructure:"trustedProxies" example:"10.0.0.0/8"` // IPs or CIDRs whose X-Forwarded-For is believed when proxyEnabled is on
+		RateLimitEnabled bool     `json:"rateLimitEnabled" mapstructure:"rateLimitEnabled" example:"true"`
+		RequestsPerMin   int      `json:"requestsPerMin" mapstructure:"requestsPerMin" example:"100" binding:"min=0"`
 	} `json:"http"`
 
 	// Auth contains authentication settings
 	Auth struct {
 		EnableLocal     bool     `json:"enableLocal" mapstructure:"enableLocal" example:"true"`
-		SessionTimeout  int      `json:"sessionTimeout" mapstructure:"sessionTimeout" example:"60" binding:"required,min=1"`
+		SessionTimeout  int      `json:"sessionTimeout" mapstructure:"sessionTimeout" example:"60" binding:"required,min=1"` // In days, lifetime of refresh tokens
 		Enable2FA       bool     `json:"enable2FA" mapstructure:"enable2FA" example:"false"`
 		JWTSecret       string   `json:"jwtSecret" mapstructure:"jwtSecret" example:"your-secret-key" binding:"required"`
-		TokenExpiration int      `json:"tokenExpiration" mapstructure:"tokenExpiration" example:"24" binding:"required,min=1"`
+		TokenExpiration int      `json:"tokenExpiration" mapstructure:"tokenExpiration" example:"24" binding:"required,min=1"` // In hours, lifetime of access tokens
 		AllowedOrigins  []string `json:"allowedOrigins" mapstructure:"allowedOrigins" example:"http://localhost:3000"`
 	} `json:"auth"`
+
+	// Analytics contains click tracking settings
+	Analytics struct {
+		IPHashSalt     string `json:"ipHashSalt" mapstructure:"ipHashSalt" example:"change-me"`
+		TopReferrers   int    `json:"topReferrers" mapstructure:"topReferrers" example:"10" binding:"min=0"`
+		RollupInterval int    `json:"rollupInterval" mapstructure:"rollupInterval" example:"5" binding:"min=0"` // In minutes, 0 disables rollups
+	} `json:"analytics"`
+
+	// Redirect contains short link resolution settings
+	Redirect struct {
+		DefaultStatus       int `json:"defaultStatus" mapstructure:"defaultStatus" example:"302" binding:"omitempty,oneof=301 302 307 308"` // Used when neither the link nor its workspace set one
+		PasswordMaxAttempts int `json:"passwordMaxAttempts" mapstructure:"passwordMaxAttempts" example:"5" binding:"min=0"`                 // Wrong link passwords before a visitor is locked out, 0 disables lockout
+		PasswordLockout     int `json:"passwordLockout" mapstructure:"passwordLockout" example:"15" binding:"min=0"`                        // In minutes
+
+		// Where visitors of expired, disabled or click-exhausted links go when
+		// neither the link nor its workspace set a fallback URL. Without one,
+		// browsers get FallbackPage (or a built-in page) and API clients a 404.
+		FallbackURL  string `json:"fallbackUrl" mapstructure:"fallbackUrl" example:"https://example.com/link-expired" binding:"omitempty,url"`
+		FallbackPage string `json:"fallbackPage" mapstructure:"fallbackPage" example:"<!DOCTYPE html><html><body><h1>This link has ended</h1></body></html>"` // HTML document
+	} `json:"redirect"`
+
+	// GeoIP contains the country lookup used by geo redirect rules
+	GeoIP struct {
+		DatabasePath string `json:"databasePath" mapstructure:"databasePath" example:"/var/lib/GeoIP/GeoLite2-Country.mmdb"` // MaxMind-format database, read at startup
+	} `json:"geoip"`
 }
 
 // ConfigResponse represents the response structure for configuration endpoints
@@ -0,0 +1,26 @@
+package models
+
+// Destination is one variant of an A/B split link. Visitors are assigned a
+// destination at random, in proportion to its weight, and keep it on later
+// visits through a cookie.
+type Destination struct {
+	ID        uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	ShortenID uint64 `json:"-" gorm:"not null;index"`
+	Position  int    `json:"-" gorm:"not null;default:0"`
+	URL       string `json:"url" example:"https://example.com/landing-a"`
+	Weight    int    `json:"weight" gorm:"not null;default:1" example:"50"`
+}
+
+// DestinationRequest describes one variant of a split link
+type DestinationRequest struct {
+	URL    string `json:"url" binding:"required,url" example:"https://example.com/landing-a"`
+	Weight int    `json:"weight" binding:"required,min=1,max=1000" example:"50"`
+}
+
+// VariantStats is the click count of one destination of a split link
+type VariantStats struct {
+	DestinationID uint64 `json:"destinationId" example:"1"`
+	URL           string `json:"url" example:"https://example.com/landing-a"`
+	Weight        int    `json:"weight" example:"50"`
+	Clicks        int64  `json:"clicks" example:"420"`
+}
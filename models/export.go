@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatJSONL ExportFormat = "jsonl"
+	ExportFormatJSON  ExportFormat = "json"
+)
+
+// ExportType is what an export contains
+type ExportType string
+
+const (
+	ExportTypeLinks ExportType = "links"
+	// ExportTypeClicks exports the click events of the selected links
+	ExportTypeClicks ExportType = "clicks"
+)
+
+// ExportQuery holds the query parameters of the export endpoint
+type ExportQuery struct {
+	LinkFilterQuery
+	Format ExportFormat `form:"format" binding:"omitempty,oneof=csv jsonl json" example:"csv"`
+	Type   ExportType   `form:"type" binding:"omitempty,oneof=links clicks" example:"links"`
+	// From and To bound the time of exported click events, RFC3339 or
+	// YYYY-MM-DD
+	From string `form:"from" example:"2024-01-01"`
+	To   string `form:"to" example:"2024-02-01"`
+}
+
+// ClickFilter selects the click events of the links Links selects, within
+// From and To when set
+type ClickFilter struct {
+	Links ShortenFilter
+	From  *time.Time
+	To    *time.Time
+}
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// ImportSource is the shortener an export file comes from
+type ImportSource string
+
+const (
+	ImportSourceBitly  ImportSource = "bitly"
+	ImportSourceYOURLS ImportSource = "yourls"
+)
+
+// ImportFormat is the file format of an export
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"
+	ImportFormatJSON ImportFormat = "json"
+)
+
+// ImportStatus is where an import job is in its life
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+// MaxImportIssues bounds how many issues a job keeps. Later issues are
+// still counted.
+const MaxImportIssues = 1000
+
+// ImportJob tracks the import of an export file into a workspace. Jobs run
+// in the background; poll them until Status is no longer running. Jobs are
+// not resumable: a job still running when Portus restarts is marked failed
+// on startup, and importing the file again skips the links it already
+// created as conflicts.
+type ImportJob struct {
+	ID     uint64       `json:"id" example:"1"`
+	Source ImportSource `json:"source" gorm:"not null" example:"bitly"`
+	Format ImportFormat `json:"format" gorm:"not null" example:"csv"`
+	Status ImportStatus `json:"status" gorm:"not null" example:"running"`
+
+	// Total is the number of links in the file. Processed counts those
+	// handled so far, as Imported, Conflicts or Failed.
+	Total     int `json:"total" example:"1200"`
+	Processed int `json:"processed" example:"400"`
+	Imported  int `json:"imported" example:"395"`
+	Conflicts int `json:"conflicts" example:"3"`
+	Failed    int `json:"failed" example:"2"`
+
+	// Issues lists the links that were not imported and why, at most
+	// MaxImportIssues of them
+	Issues []ImportIssue `json:"issues" gorm:"serializer:json"`
+	// Error is why a failed job stopped early
+	Error string `json:"error,omitempty"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	// Jobs are visible to their creator and to holders of links:admin in
+	// their workspace, like the links they create
+	WorkspaceID   uint64  `json:"-" gorm:"not null;index"`
+	OwnerUserID   *uint64 `json:"-" gorm:"index"`
+	OwnerAPIKeyID *uint64 `json:"-" gorm:"index"`
+}
+
+// ImportIssue is a link of an export that was not imported
+type ImportIssue struct {
+	// Row is the 1-based position of the link in the file, after the CSV
+	// header
+	Row     int       `json:"row" example:"12"`
+	Code    string    `json:"code,omitempty" example:"spring"`
+	Type    ErrorType `json:"type" example:"CONFLICT"`
+	Message string    `json:"message" example:"The short code already exists"`
+}
+
+// ImportRequest describes an uploaded export file
+type ImportRequest struct {
+	Source ImportSource `form:"source" binding:"required,oneof=bitly yourls" example:"bitly"`
+	// Format defaults to the extension of the uploaded file
+	Format ImportFormat `form:"format" binding:"omitempty,oneof=csv json" example:"csv"`
+}
+
+// ImportRecord is one link of an export, mapped onto Portus fields
+type ImportRecord struct {
+	Row       int
+	Code      string
+	URL       string
+	CreatedAt time.Time
+	Clicks    uint64
+}
@@ -92,3 +92,12 @@ func NewShortenResponse(shorten *Shorten, message string) APIResponse[ShortenDat
 		Data:    ShortenData{Shorten: shorten},
 	}
 }
+
+// PaginatedData wraps a single page of a listing
+type PaginatedData[T any] struct {
+	Items      []T   `json:"items"`
+	Page       int   `json:"page" example:"1"`
+	PageSize   int   `json:"pageSize" example:"20"`
+	TotalItems int64 `json:"totalItems" example:"42"`
+	TotalPages int   `json:"totalPages" example:"3"`
+}
@@ -0,0 +1,55 @@
+package models
+
+// Role is a member's level of access within a workspace
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+	RoleOwner  Role = "owner"
+)
+
+// Roles lists every role, from least to most privileged
+var Roles = []Role{RoleViewer, RoleEditor, RoleAdmin, RoleOwner}
+
+// rolePermissions is the permission matrix: the scopes each role grants
+// within its workspace. Instance-wide scopes (config:admin, users:admin,
+// workspaces:admin) are never granted by a role, only to admin users.
+var rolePermissions = map[Role][]Scope{
+	RoleViewer: {
+		ScopeLinksRead,
+		ScopeAnalyticsRead,
+	},
+	RoleEditor: {
+		ScopeLinksRead,
+		ScopeLinksWrite,
+		ScopeAnalyticsRead,
+	},
+	RoleAdmin: {
+		ScopeLinksRead,
+		ScopeLinksWrite,
+		ScopeLinksAdmin,
+		ScopeAnalyticsRead,
+		ScopeKeysAdmin,
+	},
+	RoleOwner: {
+		ScopeLinksRead,
+		ScopeLinksWrite,
+		ScopeLinksAdmin,
+		ScopeAnalyticsRead,
+		ScopeKeysAdmin,
+		ScopeMembersAdmin,
+	},
+}
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Scopes returns the scopes granted by r
+func (r Role) Scopes() []Scope {
+	return rolePermissions[r]
+}
@@ -0,0 +1,45 @@
+package models
+
+// Device is the platform a visitor's browser runs on
+type Device string
+
+const (
+	DeviceIOS     Device = "ios"
+	DeviceAndroid Device = "android"
+	DeviceDesktop Device = "desktop"
+)
+
+// RedirectRule sends visitors on a device or from a country to URL instead
+// of the link's original URL, e.g. to an app store listing, an in-app deep
+// link or a regional site. A rule with both conditions needs both to match.
+// A link's rules are tried in order and the first match wins.
+type RedirectRule struct {
+	ID        uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	ShortenID uint64 `json:"-" gorm:"not null;index"`
+	Position  int    `json:"-" gorm:"not null;default:0"`
+	Device    Device `json:"device,omitempty" example:"ios"`
+	// Country is an ISO 3166-1 alpha-2 code, resolved from the visitor's IP
+	Country string `json:"country,omitempty" example:"DE"`
+	URL     string `json:"url" example:"https://apps.apple.com/app/id123456789"`
+}
+
+// Matches reports whether the rule applies to a visitor on device from
+// country. An unknown country matches no country rule.
+func (r *RedirectRule) Matches(device Device, country string) bool {
+	if r.Device != "" && r.Device != device {
+		return false
+	}
+	if r.Country != "" && r.Country != country {
+		return false
+	}
+	return true
+}
+
+// RedirectRuleRequest describes one redirect rule of a link. It needs a
+// device, a country or both. URL may be an app deep link such as
+// myapp://product/42.
+type RedirectRuleRequest struct {
+	Device  Device `json:"device,omitempty" binding:"required_without=Country,omitempty,oneof=ios android desktop" example:"ios"`
+	Country string `json:"country,omitempty" binding:"required_without=Device,omitempty,iso3166_1_alpha2" example:"DE"`
+	URL     string `json:"url" binding:"required,url" example:"https://apps.apple.com/app/id123456789"`
+}
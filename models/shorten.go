@@ -5,11 +5,85 @@ import "time"
 type Shorten struct {
 	ID          uint64    `json:"id" example:"1"`
 	OriginalURL string    `json:"originalUrl" binding:"required" example:"https://example.com/some/long/path"`
-	ShortCode   string    `json:"shortCode" example:"abc123"`
+	ShortCode   string    `json:"shortCode" gorm:"uniqueIndex:idx_shortens_domain_code,priority:2" example:"abc123"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 	ClickCount  uint64    `json:"clickCount" example:"0"`
 	ExpiresAt   time.Time `json:"expiresAt,omitempty"`
+
+	// ActivatesAt keeps the link dormant until then. Zero means the link is
+	// active as soon as it is created.
+	ActivatesAt time.Time `json:"activatesAt,omitempty"`
+
+	// MaxClicks is the number of redirects the link serves before it stops
+	// resolving, 1 for a one-time link. Zero means unlimited.
+	MaxClicks uint64 `json:"maxClicks,omitempty" gorm:"not null;default:0" example:"1"`
+
+	// Disabled links stop redirecting until they are enabled again
+	Disabled bool `json:"disabled" gorm:"not null;default:false" example:"false"`
+
+	// FallbackURL is where visitors are sent once the link has expired, is
+	// disabled or has used up its clicks. Empty falls back to the workspace,
+	// then the global fallback.
+	FallbackURL string `json:"fallbackUrl,omitempty" example:"https://example.com/offer-ended"`
+
+	// Rules pick another destination by the visitor's device and country,
+	// in order
+	Rules []RedirectRule `json:"rules,omitempty" gorm:"foreignKey:ShortenID;constraint:OnDelete:CASCADE"`
+
+	// Destinations split the visitors no rule matches across several URLs
+	// by weight. Without destinations, they go to OriginalURL.
+	Destinations []Destination `json:"destinations,omitempty" gorm:"foreignKey:ShortenID;constraint:OnDelete:CASCADE"`
+
+	// UTM parameters are added to the destination on redirect. Campaign names
+	// a template in the workspace settings that fills the parameters UTM
+	// leaves empty.
+	UTM      UTMParams `json:"utm" gorm:"serializer:json"`
+	Campaign string    `json:"campaign,omitempty" example:"spring-sale"`
+
+	// Passthrough appends the path after the code and the query string of
+	// the short URL to the destination, e.g. /abc123/docs?ref=x
+	Passthrough bool `json:"passthrough" gorm:"not null;default:false" example:"false"`
+
+	// Tags label the link for filtering and per-tag click counts
+	Tags []Tag `json:"tags,omitempty" gorm:"many2many:shorten_tags;constraint:OnDelete:CASCADE"`
+
+	// RedirectStatus is the HTTP status of the redirect: 301, 302, 307 or
+	// 308. Zero falls back to the workspace, then the global default.
+	RedirectStatus int `json:"redirectStatus,omitempty" example:"301"`
+
+	// PasswordHash is the bcrypt hash of the password visitors must enter
+	// before being redirected, empty for public links
+	PasswordHash string `json:"-"`
+
+	// Codes are unique per domain, since redirects look links up by domain
+	// and code alone. Domain is the workspace's domain, empty for
+	// app.appUrl, and follows it when it changes.
+	WorkspaceID uint64 `json:"workspaceId" gorm:"not null;default:0;index" example:"1"`
+	Domain      string `json:"domain,omitempty" gorm:"not null;default:'';uniqueIndex:idx_shortens_domain_code,priority:1" example:"go.acme.com"`
+
+	// The user or API key that created the link. Links created before
+	// ownership was tracked have neither and are only visible to links:admin.
+	OwnerUserID   *uint64 `json:"ownerUserId,omitempty" gorm:"index" example:"1"`
+	OwnerAPIKeyID *uint64 `json:"ownerApiKeyId,omitempty" gorm:"index"`
+}
+
+// HasPassword reports whether visitors must enter a password to follow the link
+func (s *Shorten) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// LinkOwner restricts link queries to the links of a single user or API key
+type LinkOwner struct {
+	UserID   *uint64
+	APIKeyID *uint64
+}
+
+// LinkScope restricts link queries to one workspace and, when Owner is set,
+// to the links of one user or API key within it
+type LinkScope struct {
+	WorkspaceID uint64
+	Owner       *LinkOwner
 }
 
 // ShortenRequest represents the request to create a shortened URL
@@ -17,11 +91,93 @@ type ShortenRequest struct {
 	OriginalURL  string `json:"originalUrl" binding:"required"`
 	CustomCode   string `json:"customCode,omitempty"`
 	ExpiresAfter int    `json:"expiresAfter,omitempty"` // In days
+	// ExpiresAt is an absolute expiry time in RFC3339 and wins over expiresAfter
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2025-07-01T00:00:00Z"`
+	// ActivatesAt keeps the link dormant until this RFC3339 time
+	ActivatesAt *time.Time `json:"activatesAt,omitempty" example:"2025-06-01T09:00:00Z"`
+	// Permanent (301, 308) redirects are cached by browsers, so repeat visits
+	// may not be counted. Omit to use the workspace or global default.
+	RedirectStatus int `json:"redirectStatus,omitempty" binding:"omitempty,oneof=301 302 307 308" example:"302"`
+	// MaxClicks limits how often the link redirects, 1 for a one-time link.
+	// On update, omit it to keep the current limit and send 0 to remove it.
+	MaxClicks *uint64 `json:"maxClicks,omitempty" example:"1"`
+	// Disabled stops the link from redirecting. Omit to keep the current state.
+	Disabled *bool `json:"disabled,omitempty" example:"false"`
+	// FallbackURL receives visitors once the link has expired, is disabled
+	// or has used up its clicks
+	FallbackURL string `json:"fallbackUrl,omitempty" binding:"omitempty,url" example:"https://example.com/offer-ended"`
+	// RemoveFallbackURL clears the link's fallback URL on update
+	RemoveFallbackURL bool `json:"removeFallbackUrl,omitempty" example:"false"`
+	// Rules send visitors on some devices or from some countries elsewhere,
+	// first match wins. On update, omit them to keep the current rules and
+	// send [] to remove them.
+	Rules []RedirectRuleRequest `json:"rules,omitempty" binding:"omitempty,max=20,dive"`
+	// Destinations make the link an A/B split across weighted URLs. On
+	// update, omit them to keep the current ones and send [] to remove them.
+	Destinations []DestinationRequest `json:"destinations,omitempty" binding:"omitempty,max=10,dive"`
+	// UTM parameters to add to the destination on redirect. On update, omit
+	// them to keep the current ones and send {} to remove them.
+	UTM *UTMParams `json:"utm,omitempty"`
+	// Campaign names a UTM template of the workspace. On update, omit it to
+	// keep the current one and send "" to remove it.
+	Campaign *string `json:"campaign,omitempty" binding:"omitempty,max=64" example:"spring-sale"`
+	// Passthrough appends the path and query string of the short URL to the
+	// destination. Omit it on update to keep the current setting.
+	Passthrough *bool `json:"passthrough,omitempty" example:"true"`
+	// Tags label the link. Missing tags are created in the workspace. On
+	// update, omit them to keep the current tags and send [] to remove them.
+	Tags []string `json:"tags,omitempty" binding:"omitempty,max=20,dive,min=1,max=64" example:"spring-sale,newsletter"`
+	// Password protects the link. Visitors enter it in a prompt, or API
+	// clients send it in the X-Link-Password header.
+	Password string `json:"password,omitempty" binding:"omitempty,min=4,max=72" example:"launch-day"`
+	// RemovePassword makes a protected link public again on update
+	RemovePassword bool `json:"removePassword,omitempty" example:"false"`
+}
+
+// BatchShortenRequest represents the request to create several shortened
+// URLs at once. Each link is validated on its own, so one invalid link does
+// not reject the others unless the batch is atomic.
+type BatchShortenRequest struct {
+	Links []ShortenRequest `json:"links" binding:"required,min=1,max=500"`
+	// Atomic creates all links or none. By default the valid links are
+	// created even when others fail.
+	Atomic bool `json:"atomic,omitempty" example:"false"`
+}
+
+// BatchItemError explains why one link of a batch was not created
+type BatchItemError struct {
+	Type    ErrorType `json:"type" example:"CONFLICT"`
+	Message string    `json:"message" example:"The specified short code already exists"`
+}
+
+// BatchShortenResult is the outcome of one link of a batch. Results are in
+// request order and carry either Data or Error.
+type BatchShortenResult struct {
+	Index int             `json:"index" example:"0"`
+	Data  *ShortenData    `json:"data,omitempty"`
+	Error *BatchItemError `json:"error,omitempty"`
+}
+
+// BatchShortenResponse lists the outcome of every link of a batch
+type BatchShortenResponse struct {
+	Created int                  `json:"created" example:"2"`
+	Failed  int                  `json:"failed" example:"1"`
+	Results []BatchShortenResult `json:"results"`
+}
+
+// RedirectResult is where and how a short link redirects a visitor
+type RedirectResult struct {
+	URL        string
+	StatusCode int
+	// DestinationID is the split destination the visitor was assigned to,
+	// zero for links without destinations
+	DestinationID uint64
 }
 
 type ShortenData struct {
-	Shorten  *Shorten `json:"shorten"`
-	ShortURL string   `json:"shortUrl"`
+	Shorten           *Shorten `json:"shorten"`
+	ShortURL          string   `json:"shortUrl"`
+	PasswordProtected bool     `json:"passwordProtected"`
 }
 
 type GetByOriginalURLRequest struct {
@@ -31,3 +187,36 @@ type GetByOriginalURLRequest struct {
 	CustomCode        string `json:"customCode,omitempty"`
 	// TODO: allow duplicates? like create more copies if someone wants multiple short urls to the same domain. ??
 }
+
+// LinkFilterQuery holds the query parameters that select links, shared by
+// the listing and export endpoints
+type LinkFilterQuery struct {
+	Search      string `form:"q" example:"example.com"`
+	CreatedFrom string `form:"createdFrom" example:"2023-01-01"`
+	CreatedTo   string `form:"createdTo" example:"2023-02-01T00:00:00Z"`
+	Expired     *bool  `form:"expired" example:"false"`
+	Tag         string `form:"tag" binding:"omitempty,max=64" example:"spring-sale"`
+}
+
+// ShortenListQuery holds the query parameters of the link listing endpoint
+type ShortenListQuery struct {
+	LinkFilterQuery
+	Page     int    `form:"page" binding:"omitempty,min=1" example:"1"`
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1" example:"20"`
+	SortBy   string `form:"sortBy" binding:"omitempty,oneof=createdAt clickCount" example:"createdAt"`
+	Order    string `form:"order" binding:"omitempty,oneof=asc desc" example:"desc"`
+}
+
+// ShortenFilter is the validated form of ShortenListQuery used by the repository
+type ShortenFilter struct {
+	Scope       LinkScope
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Expired     *bool
+	Tag         string
+	SortBy      string
+	Descending  bool
+	Offset      int
+	Limit       int
+}
@@ -0,0 +1,15 @@
+package models
+
+// Tag labels links within a workspace. Names are unique per workspace.
+type Tag struct {
+	ID          uint64 `json:"-" gorm:"primaryKey"`
+	WorkspaceID uint64 `json:"-" gorm:"not null;uniqueIndex:idx_tags_name,priority:1"`
+	Name        string `json:"name" gorm:"not null;uniqueIndex:idx_tags_name,priority:2" example:"spring-sale"`
+}
+
+// TagStats totals the links carrying a tag and their clicks
+type TagStats struct {
+	Name   string `json:"name" example:"spring-sale"`
+	Links  int64  `json:"links" example:"12"`
+	Clicks int64  `json:"clicks" example:"3400"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the
+// authenticator device is lost. Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint64 `gorm:"primaryKey"`
+	UserID    uint64 `gorm:"index"`
+	CodeHash  string `gorm:"index"`
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// TwoFactorSetupData is returned when a user starts TOTP enrollment
+type TwoFactorSetupData struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauthUri" example:"otpauth://totp/Portus:jane?issuer=Portus&secret=JBSWY3DPEHPK3PXP"`
+	QRCode     string `json:"qrCode" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+// TwoFactorCodeRequest carries a code from the user's authenticator app
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// TwoFactorDisableRequest turns TOTP off; either code is accepted
+type TwoFactorDisableRequest struct {
+	Code         string `json:"code,omitempty" example:"123456"`
+	RecoveryCode string `json:"recoveryCode,omitempty" example:"ABCD-EFGH-IJKL-MNOP"`
+}
+
+// RecoveryCodesData carries freshly generated recovery codes. They are only
+// shown once.
+type RecoveryCodesData struct {
+	RecoveryCodes []string `json:"recoveryCodes" example:"ABCD-EFGH-IJKL-MNOP"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// User is a local account that logs in with a username and password
+type User struct {
+	ID           uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	Username     string `json:"username" gorm:"uniqueIndex" example:"jane"`
+	Email        string `json:"email" gorm:"uniqueIndex" example:"jane@example.com"`
+	PasswordHash string `json:"-"`
+	IsAdmin      bool   `json:"isAdmin" example:"false"`
+	Disabled     bool   `json:"disabled" example:"false"`
+
+	// TOTP two-factor authentication. TOTPSecret is set during enrollment
+	// and only trusted once TOTPEnabled is set; TOTPLastStep blocks replays.
+	TOTPEnabled  bool   `json:"twoFactorEnabled" example:"false"`
+	TOTPSecret   string `json:"-"`
+	TOTPLastStep int64  `json:"-"`
+
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+}
+
+// Scopes returns the instance-wide scopes of the user's sessions. Everyone
+// else is granted scopes by their role in the workspace they operate on.
+func (u *User) Scopes() []Scope {
+	if u.IsAdmin {
+		return AllScopes
+	}
+	return nil
+}
+
+// CreateUserRequest represents the request to create a local user
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=64" example:"jane"`
+	Email    string `json:"email" binding:"required,email" example:"jane@example.com"`
+	Password string `json:"password" binding:"required,min=8,max=72" example:"correct-horse-battery"`
+	IsAdmin  bool   `json:"isAdmin" example:"false"`
+	// Role in the caller's workspace, which the new user joins. Defaults to editor.
+	Role Role `json:"role,omitempty" binding:"omitempty,oneof=viewer editor admin owner" example:"editor"`
+}
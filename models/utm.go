@@ -0,0 +1,80 @@
+package models
+
+import "net/url"
+
+// UTMParams are the campaign tracking parameters added to a link's
+// destination when it redirects
+type UTMParams struct {
+	Source   string `json:"source,omitempty" binding:"max=256" example:"newsletter"`
+	Medium   string `json:"medium,omitempty" binding:"max=256" example:"email"`
+	Campaign string `json:"campaign,omitempty" binding:"max=256" example:"spring-sale"`
+	Term     string `json:"term,omitempty" binding:"max=256" example:"running shoes"`
+	Content  string `json:"content,omitempty" binding:"max=256" example:"header-link"`
+}
+
+// Merge returns p with its empty parameters taken from defaults
+func (p UTMParams) Merge(defaults UTMParams) UTMParams {
+	if p.Source == "" {
+		p.Source = defaults.Source
+	}
+	if p.Medium == "" {
+		p.Medium = defaults.Medium
+	}
+	if p.Campaign == "" {
+		p.Campaign = defaults.Campaign
+	}
+	if p.Term == "" {
+		p.Term = defaults.Term
+	}
+	if p.Content == "" {
+		p.Content = defaults.Content
+	}
+	return p
+}
+
+// Query returns the non-empty parameters as utm_* query parameters, in the
+// order they are conventionally written
+func (p UTMParams) Query() [][2]string {
+	var query [][2]string
+	for _, param := range [][2]string{
+		{"utm_source", p.Source},
+		{"utm_medium", p.Medium},
+		{"utm_campaign", p.Campaign},
+		{"utm_term", p.Term},
+		{"utm_content", p.Content},
+	} {
+		if param[1] != "" {
+			query = append(query, param)
+		}
+	}
+	return query
+}
+
+// AddTo returns destination with the parameters appended to its query
+// string. Parameters the destination already has are left alone, and so are
+// destinations that are not http(s) URLs, such as app deep links.
+func (p UTMParams) AddTo(destination string) string {
+	query := p.Query()
+	if len(query) == 0 {
+		return destination
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return destination
+	}
+
+	existing := u.Query()
+	rawQuery := u.RawQuery
+	for _, param := range query {
+		if existing.Has(param[0]) {
+			continue
+		}
+		if rawQuery != "" {
+			rawQuery += "&"
+		}
+		rawQuery += url.QueryEscape(param[0]) + "=" + url.QueryEscape(param[1])
+	}
+	u.RawQuery = rawQuery
+	return u.String()
+}
@@ -0,0 +1,104 @@
+package models
+
+import "testing"
+
+func TestUTMParamsMerge(t *testing.T) {
+	template := UTMParams{Source: "newsletter", Medium: "email", Campaign: "spring-sale"}
+
+	tests := []struct {
+		name     string
+		params   UTMParams
+		defaults UTMParams
+		want     UTMParams
+	}{
+		{
+			name:     "empty link takes the template",
+			defaults: template,
+			want:     template,
+		},
+		{
+			name:     "link parameters override the template",
+			params:   UTMParams{Source: "twitter", Content: "header-link"},
+			defaults: template,
+			want:     UTMParams{Source: "twitter", Medium: "email", Campaign: "spring-sale", Content: "header-link"},
+		},
+		{
+			name:   "no template keeps the link parameters",
+			params: UTMParams{Term: "running shoes"},
+			want:   UTMParams{Term: "running shoes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.Merge(tt.defaults); got != tt.want {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTMParamsAddTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      UTMParams
+		destination string
+		want        string
+	}{
+		{
+			name:        "no parameters leaves the destination alone",
+			destination: "https://example.com/a?b=c",
+			want:        "https://example.com/a?b=c",
+		},
+		{
+			name:        "parameters are appended in conventional order",
+			params:      UTMParams{Content: "header", Source: "newsletter", Medium: "email"},
+			destination: "https://example.com/a",
+			want:        "https://example.com/a?utm_source=newsletter&utm_medium=email&utm_content=header",
+		},
+		{
+			name:        "existing query is kept",
+			params:      UTMParams{Source: "newsletter"},
+			destination: "https://example.com/a?ref=x",
+			want:        "https://example.com/a?ref=x&utm_source=newsletter",
+		},
+		{
+			name:        "existing utm parameter on the destination wins",
+			params:      UTMParams{Source: "newsletter", Medium: "email"},
+			destination: "https://example.com/a?utm_source=partner",
+			want:        "https://example.com/a?utm_source=partner&utm_medium=email",
+		},
+		{
+			name:        "values are escaped",
+			params:      UTMParams{Term: "running shoes & socks"},
+			destination: "https://example.com/",
+			want:        "https://example.com/?utm_term=running+shoes+%26+socks",
+		},
+		{
+			name:        "fragment stays at the end",
+			params:      UTMParams{Source: "newsletter"},
+			destination: "https://example.com/a#section",
+			want:        "https://example.com/a?utm_source=newsletter#section",
+		},
+		{
+			name:        "deep links are left alone",
+			params:      UTMParams{Source: "newsletter"},
+			destination: "myapp://product/42",
+			want:        "myapp://product/42",
+		},
+		{
+			name:        "unparsable destination is left alone",
+			params:      UTMParams{Source: "newsletter"},
+			destination: "https://exa mple.com/%zz",
+			want:        "https://exa mple.com/%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.AddTo(tt.destination); got != tt.want {
+				t.Errorf("AddTo(%q) = %q, want %q", tt.destination, got, tt.want)
+			}
+		})
+	}
+}
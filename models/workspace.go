@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// DefaultWorkspaceSlug identifies the workspace created on first start. Links,
+// API keys and users that predate workspaces are moved into it.
+const DefaultWorkspaceSlug = "default"
+
+// Workspace is a tenant that owns links, members, API keys and settings
+type Workspace struct {
+	ID   uint64 `json:"id" gorm:"primaryKey" example:"1"`
+	Name string `json:"name" example:"Acme Shoes"`
+	Slug string `json:"slug" gorm:"uniqueIndex" example:"acme-shoes"`
+
+	// Domain is the host the workspace's short links are served on, e.g.
+	// "go.acme.com". Without one, links are served on app.appUrl.
+	Domain *string `json:"domain,omitempty" gorm:"uniqueIndex" example:"go.acme.com"`
+
+	Settings  WorkspaceSettings `json:"settings" gorm:"serializer:json"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// WorkspaceSettings holds per-workspace defaults for link creation
+type WorkspaceSettings struct {
+	// DefaultExpiresAfter applies to links created without expiresAfter, in days
+	DefaultExpiresAfter int `json:"defaultExpiresAfter,omitempty" binding:"min=0" example:"30"`
+	// DefaultRedirectStatus applies to links without a redirect status.
+	// Zero falls back to redirect.defaultStatus.
+	DefaultRedirectStatus int `json:"defaultRedirectStatus,omitempty" binding:"omitempty,oneof=301 302 307 308" example:"302"`
+	// FallbackURL receives visitors of unavailable links without their own.
+	// Empty falls back to redirect.fallbackUrl.
+	FallbackURL string `json:"fallbackUrl,omitempty" binding:"omitempty,url" example:"https://acme.com/expired"`
+	// Campaigns are named UTM templates links can refer to by name
+	Campaigns map[string]UTMParams `json:"campaigns,omitempty" binding:"omitempty,max=50,dive,keys,min=1,max=64,endkeys,required"`
+}
+
+// WorkspaceMember grants a local user access to a workspace. Members that
+// predate roles become editors, which matches their previous access.
+type WorkspaceMember struct {
+	WorkspaceID uint64    `json:"workspaceId" gorm:"primaryKey"`
+	UserID      uint64    `json:"userId" gorm:"primaryKey;index"`
+	Role        Role      `json:"role" gorm:"not null;default:'editor'" example:"editor"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WorkspaceMemberData is a member of a workspace together with its role
+type WorkspaceMemberData struct {
+	User *User `json:"user"`
+	Role Role  `json:"role" example:"editor"`
+}
+
+// WorkspaceRequest represents the request to create or update a workspace
+type WorkspaceRequest struct {
+	Name     string            `json:"name" binding:"required,max=128" example:"Acme Shoes"`
+	Slug     string            `json:"slug" binding:"required,max=64" example:"acme-shoes"`
+	Domain   string            `json:"domain,omitempty" binding:"omitempty,hostname" example:"go.acme.com"`
+	Settings WorkspaceSettings `json:"settings"`
+}
+
+// AddWorkspaceMemberRequest represents the request to add a user to a
+// workspace. Role defaults to editor.
+type AddWorkspaceMemberRequest struct {
+	UserID uint64 `json:"userId" binding:"required" example:"2"`
+	Role   Role   `json:"role,omitempty" binding:"omitempty,oneof=viewer editor admin owner" example:"editor"`
+}
+
+// UpdateWorkspaceMemberRequest represents the request to change a member's role
+type UpdateWorkspaceMemberRequest struct {
+	Role Role `json:"role" binding:"required,oneof=viewer editor admin owner" example:"admin"`
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepository defines the data access interface for click analytics
+type AnalyticsRepository interface {
+	RecordClick(ctx context.Context, click *models.Click) error
+	LastClickAt(ctx context.Context, shortenID uint64) (*time.Time, error)
+	TopReferrers(ctx context.Context, shortenID uint64, limit int) ([]models.ReferrerCount, error)
+	ClicksByDestination(ctx context.Context, shortenID uint64) (map[uint64]int64, error)
+	RollupHourly(ctx context.Context, since time.Time) error
+	RollupDaily(ctx context.Context, since time.Time) error
+	LatestRollup(ctx context.Context, interval models.RollupInterval) (*time.Time, error)
+	GetRollups(ctx context.Context, shortenID uint64, interval models.RollupInterval, from, to time.Time) ([]models.ClickRollup, error)
+	EachClick(ctx context.Context, filter models.ClickFilter, fn func([]models.Click) error) error
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository creates a new analytics repository
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+// RecordClick stores the click event. The link's counter is claimed
+// separately by the redirect, see ShortenRepository.ClaimClick.
+func (r *analyticsRepository) RecordClick(ctx context.Context, click *models.Click) error {
+	return r.db.WithContext(ctx).Create(click).Error
+}
+
+func (r *analyticsRepository) LastClickAt(ctx context.Context, shortenID uint64) (*time.Time, error) {
+	var click models.Click
+	result := r.db.WithContext(ctx).
+		Where("shorten_id = ?", shortenID).
+		Order(`"timestamp" desc`).
+		First(&click)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &click.Timestamp, nil
+}
+
+func (r *analyticsRepository) TopReferrers(ctx context.Context, shortenID uint64, limit int) ([]models.ReferrerCount, error) {
+	var referrers []models.ReferrerCount
+	result := r.db.WithContext(ctx).
+		Model(&models.Click{}).
+		Select("referrer, count(*) as clicks").
+		Where("shorten_id = ?", shortenID).
+		Group("referrer").
+		Order("clicks desc").
+		Limit(limit).
+		Scan(&referrers)
+
+	return referrers, result.Error
+}
+
+// ClicksByDestination counts the clicks of a split link per destination ID.
+// Clicks from before the link was split count towards destination 0.
+func (r *analyticsRepository) ClicksByDestination(ctx context.Context, shortenID uint64) (map[uint64]int64, error) {
+	var rows []struct {
+		DestinationID uint64
+		Clicks        int64
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.Click{}).
+		Select("destination_id, count(*) as clicks").
+		Where("shorten_id = ?", shortenID).
+		Group("destination_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	clicks := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		clicks[row.DestinationID] = row.Clicks
+	}
+	return clicks, nil
+}
+
+// RollupHourly recomputes every hourly bucket starting at or after since from
+// the raw click events. Buckets are rewritten, so overlapping runs are safe.
+func (r *analyticsRepository) RollupHourly(ctx context.Context, since time.Time) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO click_rollups (shorten_id, short_code, bucket_interval, bucket_start, clicks)
+		SELECT shorten_id, short_code, ?, date_trunc('hour', "timestamp", 'UTC'), count(*)
+		FROM clicks
+		WHERE "timestamp" >= ?
+		GROUP BY shorten_id, short_code, date_trunc('hour', "timestamp", 'UTC')
+		ON CONFLICT (shorten_id, bucket_interval, bucket_start)
+		DO UPDATE SET clicks = EXCLUDED.clicks`,
+		models.RollupIntervalHour, since).Error
+}
+
+// RollupDaily recomputes every daily bucket starting at or after since by
+// summing the hourly buckets, so RollupHourly must run first
+func (r *analyticsRepository) RollupDaily(ctx context.Context, since time.Time) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO click_rollups (shorten_id, short_code, bucket_interval, bucket_start, clicks)
+		SELECT shorten_id, short_code, ?, date_trunc('day', bucket_start, 'UTC'), sum(clicks)
+		FROM click_rollups
+		WHERE bucket_interval = ? AND bucket_start >= ?
+		GROUP BY shorten_id, short_code, date_trunc('day', bucket_start, 'UTC')
+		ON CONFLICT (shorten_id, bucket_interval, bucket_start)
+		DO UPDATE SET clicks = EXCLUDED.clicks`,
+		models.RollupIntervalDay, models.RollupIntervalHour, since).Error
+}
+
+func (r *analyticsRepository) LatestRollup(ctx context.Context, interval models.RollupInterval) (*time.Time, error) {
+	var rollup models.ClickRollup
+	result := r.db.WithContext(ctx).
+		Where("bucket_interval = ?", interval).
+		Order("bucket_start desc").
+		First(&rollup)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &rollup.BucketStart, nil
+}
+
+func (r *analyticsRepository) GetRollups(ctx context.Context, shortenID uint64, interval models.RollupInterval, from, to time.Time) ([]models.ClickRollup, error) {
+	var rollups []models.ClickRollup
+	result := r.db.WithContext(ctx).
+		Where("shorten_id = ? AND bucket_interval = ?", shortenID, interval).
+		Where("bucket_start >= ? AND bucket_start < ?", from, to).
+		Order("bucket_start asc").
+		Find(&rollups)
+
+	return rollups, result.Error
+}
+
+// EachClick calls fn with the click events filter selects, in batches by
+// ascending ID. An error from fn stops the iteration and is returned.
+func (r *analyticsRepository) EachClick(ctx context.Context, filter models.ClickFilter, fn func([]models.Click) error) error {
+	db := r.db.WithContext(ctx)
+	links := db.Model(&models.Shorten{}).Scopes(filtered(filter.Links)).Select("id")
+	query := db.Where("shorten_id IN (?)", links)
+
+	if filter.From != nil {
+		query = query.Where(`"timestamp" >= ?`, *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(`"timestamp" < ?`, *filter.To)
+	}
+
+	var batch []models.Click
+	return query.FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+		return fn(batch)
+	}).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the data access interface for API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	FindById(ctx context.Context, id uint64) (*models.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	List(ctx context.Context, workspaceID uint64) ([]models.APIKey, error)
+	Count(ctx context.Context) (int64, error)
+	Revoke(ctx context.Context, id uint64, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uint64, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	result := r.db.WithContext(ctx).Create(key)
+	return key, result.Error
+}
+
+func (r *apiKeyRepository) FindById(ctx context.Context, id uint64) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).First(&key, id)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context, workspaceID uint64) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	result := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("created_at desc").Find(&keys)
+	return keys, result.Error
+}
+
+func (r *apiKeyRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.APIKey{}).Count(&count)
+	return count, result.Error
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", at).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportRepository defines the data access interface for import jobs
+type ImportRepository interface {
+	Create(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error)
+	Save(ctx context.Context, job *models.ImportJob) error
+	FindById(ctx context.Context, id uint64, scope models.LinkScope) (*models.ImportJob, error)
+	FailRunning(ctx context.Context, reason string, at time.Time) (int64, error)
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository creates a new import job repository
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{
+		db: db,
+	}
+}
+
+func (r *importRepository) Create(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error) {
+	result := r.db.WithContext(ctx).Create(job)
+	return job, result.Error
+}
+
+func (r *importRepository) Save(ctx context.Context, job *models.ImportJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+// FindById returns the job with id if scope can see it
+func (r *importRepository) FindById(ctx context.Context, id uint64, scope models.LinkScope) (*models.ImportJob, error) {
+	var job models.ImportJob
+	result := r.db.WithContext(ctx).Scopes(inScope(scope)).First(&job, id)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+// FailRunning marks every running job as failed with reason and returns how
+// many there were
+func (r *importRepository) FailRunning(ctx context.Context, reason string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ImportJob{}).
+		Where("status = ?", models.ImportStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.ImportStatusFailed,
+			"error":       reason,
+			"finished_at": at,
+		})
+	return result.RowsAffected, result.Error
+}
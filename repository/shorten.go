@@ -2,21 +2,42 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"portus/models"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrDuplicateShortCode is returned when a link is created with a code that
+// is already used on its domain
+var ErrDuplicateShortCode = errors.New("short code already exists on this domain")
+
+// uniqueViolation is the Postgres error code of a unique constraint violation
+const uniqueViolation = "23505"
+
 // ShortenRepository defines the data access interface for URL shortening
 type ShortenRepository interface {
 	GetAll(ctx context.Context) ([]models.Shorten, error)
+	List(ctx context.Context, filter models.ShortenFilter) ([]models.Shorten, int64, error)
+	Each(ctx context.Context, filter models.ShortenFilter, fn func([]models.Shorten) error) error
 	FindById(ctx context.Context, id uint64) (*models.Shorten, error)
-	FindByCode(ctx context.Context, code string) (*models.Shorten, error)
+	FindByCode(ctx context.Context, domain, code string) (*models.Shorten, error)
+	FindScopedByCode(ctx context.Context, code string, scope models.LinkScope) (*models.Shorten, error)
 	Create(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error)
+	CreateAll(ctx context.Context, shortens []*models.Shorten) error
 	Update(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error)
-	Delete(ctx context.Context, code string) (string, error)
-	IncrementClickCount(ctx context.Context, code string) (*models.Shorten, error)
-	FindByOriginalURL(ctx context.Context, url string) (*models.Shorten, error)
+	Delete(ctx context.Context, code string, scope models.LinkScope) (bool, error)
+	ClaimClick(ctx context.Context, id uint64) (bool, error)
+	FindByOriginalURL(ctx context.Context, url string, scope models.LinkScope) (*models.Shorten, error)
+	Select(ctx context.Context, selection models.LinkSelection) ([]models.Shorten, error)
+	DeleteAll(ctx context.Context, ids []uint64) error
+	ExpireAll(ctx context.Context, ids []uint64, at time.Time) error
+	Retag(ctx context.Context, workspaceID uint64, ids []uint64, add, remove []string) error
 }
 
 type shortenRepository struct {
@@ -37,9 +58,125 @@ func (r *shortenRepository) GetAll(ctx context.Context) ([]models.Shorten, error
 	return shortens, result.Error
 }
 
+// shortenSortColumns maps the public sort keys onto table columns
+var shortenSortColumns = map[string]string{
+	"createdAt":  "created_at",
+	"clickCount": "click_count",
+}
+
+// inScope limits a query to the links of scope's workspace and owner
+func inScope(scope models.LinkScope) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("workspace_id = ?", scope.WorkspaceID)
+
+		owner := scope.Owner
+		if owner == nil {
+			return db
+		}
+		if owner.UserID != nil {
+			return db.Where("owner_user_id = ?", *owner.UserID)
+		}
+		if owner.APIKeyID != nil {
+			return db.Where("owner_api_key_id = ?", *owner.APIKeyID)
+		}
+		// An owner without an identity owns nothing
+		return db.Where("1 = 0")
+	}
+}
+
+// withTargets loads the redirect rules, split destinations and tags of the
+// links a query returns, in order
+func withTargets(db *gorm.DB) *gorm.DB {
+	inOrder := func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}
+	byName := func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}
+	return db.Preload("Rules", inOrder).Preload("Destinations", inOrder).Preload("Tags", byName)
+}
+
+// likeEscaper escapes LIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// batchSize is how many rows the Each methods load at a time
+const batchSize = 500
+
+// filtered restricts a query to the links filter selects, ignoring its
+// sorting and paging
+func filtered(filter models.ShortenFilter) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		query = query.Scopes(inScope(filter.Scope))
+
+		if filter.Search != "" {
+			query = query.Where("original_url ILIKE ?", "%"+likeEscaper.Replace(filter.Search)+"%")
+		}
+		if filter.Tag != "" {
+			query = query.Scopes(taggedWith(filter.Tag))
+		}
+		if filter.CreatedFrom != nil {
+			query = query.Where("created_at >= ?", *filter.CreatedFrom)
+		}
+		if filter.CreatedTo != nil {
+			query = query.Where("created_at < ?", *filter.CreatedTo)
+		}
+		if filter.Expired != nil {
+			// Links without an expiry store the zero time
+			now := time.Now()
+			if *filter.Expired {
+				query = query.Where("expires_at > ? AND expires_at <= ?", time.Time{}, now)
+			} else {
+				query = query.Where("expires_at <= ? OR expires_at > ?", time.Time{}, now)
+			}
+		}
+		return query
+	}
+}
+
+func (r *shortenRepository) List(ctx context.Context, filter models.ShortenFilter) ([]models.Shorten, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Shorten{}).Scopes(filtered(filter))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := shortenSortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "asc"
+	if filter.Descending {
+		direction = "desc"
+	}
+
+	var shortens []models.Shorten
+	result := query.
+		Scopes(withTargets).
+		Order(column + " " + direction).
+		Order("id " + direction).
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&shortens)
+
+	return shortens, total, result.Error
+}
+
+// Each calls fn with the links filter selects, in batches of batchSize by
+// ascending ID, so they never all sit in memory. An error from fn stops the
+// iteration and is returned.
+func (r *shortenRepository) Each(ctx context.Context, filter models.ShortenFilter, fn func([]models.Shorten) error) error {
+	var batch []models.Shorten
+	return r.db.WithContext(ctx).
+		Scopes(filtered(filter), withTargets).
+		FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+			return fn(batch)
+		}).Error
+}
+
 func (r *shortenRepository) FindById(ctx context.Context, id uint64) (*models.Shorten, error) {
 	var shorten models.Shorten
-	result := r.db.First(&shorten, id)
+	result := r.db.WithContext(ctx).Scopes(withTargets).First(&shorten, id)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
@@ -50,9 +187,11 @@ func (r *shortenRepository) FindById(ctx context.Context, id uint64) (*models.Sh
 	return &shorten, result.Error
 }
 
-func (r *shortenRepository) FindByCode(ctx context.Context, code string) (*models.Shorten, error) {
+// FindByCode looks a link up the way redirects do, by the domain it is
+// served on and its code, across all workspaces
+func (r *shortenRepository) FindByCode(ctx context.Context, domain, code string) (*models.Shorten, error) {
 	var shorten models.Shorten
-	result := r.db.Where("short_code", code).First(&shorten)
+	result := r.db.WithContext(ctx).Scopes(withTargets).Where("domain = ? AND short_code = ?", domain, code).First(&shorten)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
@@ -63,35 +202,127 @@ func (r *shortenRepository) FindByCode(ctx context.Context, code string) (*model
 	return &shorten, result.Error
 }
 
+func (r *shortenRepository) FindScopedByCode(ctx context.Context, code string, scope models.LinkScope) (*models.Shorten, error) {
+	var shorten models.Shorten
+	result := r.db.WithContext(ctx).Scopes(inScope(scope), withTargets).Where("short_code = ?", code).First(&shorten)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &shorten, nil
+}
+
 func (r *shortenRepository) Create(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error) {
-	result := r.db.Create(&shorten)
-	return shorten, result.Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createShorten(tx, shorten)
+	})
+	return shorten, err
 }
 
-func (r *shortenRepository) Update(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error) {
-	result := r.db.Save(&shorten)
-	return shorten, result.Error
+// CreateAll stores shortens in one transaction, so either all of them are
+// created or none is
+func (r *shortenRepository) CreateAll(ctx context.Context, shortens []*models.Shorten) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, shorten := range shortens {
+			if err := createShorten(tx, shorten); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (r *shortenRepository) IncrementClickCount(ctx context.Context, code string) (*models.Shorten, error) {
-	var shorten models.Shorten
-	r.db.Where("short_code", code).First(&shorten)
+// createShorten stores shorten with its rules, destinations and tags. Tags
+// are given by name and created in the link's workspace when missing.
+func createShorten(tx *gorm.DB, shorten *models.Shorten) error {
+	tags, err := ensureTags(tx, shorten.WorkspaceID, tagNames(shorten.Tags))
+	if err != nil {
+		return err
+	}
+	shorten.Tags = tags
 
-	shorten.ClickCount = shorten.ClickCount + 1
+	// The code may have been taken since the service checked it
+	err = tx.Create(shorten).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "idx_shortens_domain_code" {
+		return ErrDuplicateShortCode
+	}
+	return err
+}
 
-	result := r.db.Save(&shorten)
-	return &shorten, result.Error
+func (r *shortenRepository) Update(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The counter is only ever changed in SQL, so a stale copy must not
+		// overwrite clicks counted since it was read
+		if err := tx.Omit("click_count", clause.Associations).Save(shorten).Error; err != nil {
+			return err
+		}
+
+		// Rules and destinations are replaced as a whole
+		if err := tx.Where("shorten_id = ?", shorten.ID).Delete(&models.RedirectRule{}).Error; err != nil {
+			return err
+		}
+		if len(shorten.Rules) > 0 {
+			for i := range shorten.Rules {
+				shorten.Rules[i].ShortenID = shorten.ID
+			}
+			if err := tx.Create(&shorten.Rules).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("shorten_id = ?", shorten.ID).Delete(&models.Destination{}).Error; err != nil {
+			return err
+		}
+		if len(shorten.Destinations) > 0 {
+			for i := range shorten.Destinations {
+				shorten.Destinations[i].ShortenID = shorten.ID
+			}
+			if err := tx.Create(&shorten.Destinations).Error; err != nil {
+				return err
+			}
+		}
+
+		// So are tags, which are given by name
+		if err := tx.Exec("DELETE FROM shorten_tags WHERE shorten_id = ?", shorten.ID).Error; err != nil {
+			return err
+		}
+		tags, err := ensureTags(tx, shorten.WorkspaceID, tagNames(shorten.Tags))
+		if err != nil {
+			return err
+		}
+		shorten.Tags = tags
+		return tagLinks(tx, []uint64{shorten.ID}, tags)
+	})
+	return shorten, err
 }
 
-func (r *shortenRepository) Delete(ctx context.Context, code string) (string, error) {
-	var shorten models.Shorten
-	result := r.db.Delete(&shorten, code)
-	return code, result.Error
+// ClaimClick counts a redirect of the link against its click limit and
+// reports whether the link still had clicks left. The check and the
+// increment are one statement, so concurrent redirects cannot overshoot.
+func (r *shortenRepository) ClaimClick(ctx context.Context, id uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Shorten{}).
+		Where("id = ? AND (max_clicks = 0 OR click_count < max_clicks)", id).
+		UpdateColumn("click_count", gorm.Expr("click_count + ?", 1))
+	return result.RowsAffected > 0, result.Error
 }
 
-func (r *shortenRepository) FindByOriginalURL(ctx context.Context, url string) (*models.Shorten, error) {
+// Delete removes the link with code if it lies within scope and reports
+// whether a link was deleted
+func (r *shortenRepository) Delete(ctx context.Context, code string, scope models.LinkScope) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Scopes(inScope(scope)).
+		Where("short_code = ?", code).
+		Delete(&models.Shorten{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *shortenRepository) FindByOriginalURL(ctx context.Context, url string, scope models.LinkScope) (*models.Shorten, error) {
 	var shorten models.Shorten
-	result := r.db.Where("original_url = ?", url).First(&shorten)
+	result := r.db.WithContext(ctx).Scopes(inScope(scope), withTargets).Where("original_url = ?", url).First(&shorten)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -100,3 +331,62 @@ func (r *shortenRepository) FindByOriginalURL(ctx context.Context, url string) (
 	}
 	return &shorten, nil
 }
+
+// Select returns the ID and code of the links matching selection, oldest
+// first
+func (r *shortenRepository) Select(ctx context.Context, selection models.LinkSelection) ([]models.Shorten, error) {
+	query := r.db.WithContext(ctx).Model(&models.Shorten{}).Scopes(inScope(selection.Scope))
+
+	if len(selection.Codes) > 0 {
+		query = query.Where("short_code IN ?", selection.Codes)
+	}
+	if selection.Tag != "" {
+		query = query.Scopes(taggedWith(selection.Tag))
+	}
+	if selection.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *selection.CreatedBefore)
+	}
+	if selection.Domain != "" {
+		// The host, after an optional userinfo, is the domain or ends in .domain
+		pattern := `^https?://([^/?#@]*@)?([^/?#@]*\.)?` + regexp.QuoteMeta(selection.Domain) + `(:[0-9]+)?([/?#]|$)`
+		query = query.Where("original_url ~* ?", pattern)
+	}
+	if selection.Unexpired {
+		query = query.Where("expires_at <= ? OR expires_at > ?", time.Time{}, time.Now())
+	}
+
+	var shortens []models.Shorten
+	result := query.Select("id", "short_code").Order("id").Find(&shortens)
+	return shortens, result.Error
+}
+
+func (r *shortenRepository) DeleteAll(ctx context.Context, ids []uint64) error {
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&models.Shorten{}).Error
+}
+
+func (r *shortenRepository) ExpireAll(ctx context.Context, ids []uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Shorten{}).
+		Where("id IN ?", ids).
+		UpdateColumns(map[string]interface{}{"expires_at": at, "updated_at": at}).Error
+}
+
+// Retag adds the tags named add to and removes those named remove from the
+// links with ids, in one transaction. Missing tags are created in
+// workspaceID.
+func (r *shortenRepository) Retag(ctx context.Context, workspaceID uint64, ids []uint64, add, remove []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(remove) > 0 {
+			if err := tx.Exec(`DELETE FROM shorten_tags WHERE shorten_id IN ?
+				AND tag_id IN (SELECT id FROM tags WHERE workspace_id = ? AND name IN ?)`,
+				ids, workspaceID, remove).Error; err != nil {
+				return err
+			}
+		}
+
+		tags, err := ensureTags(tx, workspaceID, add)
+		if err != nil {
+			return err
+		}
+		return tagLinks(tx, ids, tags)
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"portus/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ensureTags returns the tags of workspaceID named names, creating the ones
+// that do not exist yet
+func ensureTags(tx *gorm.DB, workspaceID uint64, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{WorkspaceID: workspaceID, Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	// Tags that already existed come back without an ID, so read them all
+	var existing []models.Tag
+	err := tx.Where("workspace_id = ? AND name IN ?", workspaceID, names).Order("name").Find(&existing).Error
+	return existing, err
+}
+
+// tagLinks attaches tags to the links with ids, keeping the tags they
+// already carry
+func tagLinks(tx *gorm.DB, ids []uint64, tags []models.Tag) error {
+	if len(ids) == 0 || len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]interface{}, 0, len(ids)*len(tags))
+	for _, id := range ids {
+		for _, tag := range tags {
+			rows = append(rows, map[string]interface{}{"shorten_id": id, "tag_id": tag.ID})
+		}
+	}
+	return tx.Table("shorten_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error
+}
+
+// tagNames returns the names of tags
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// taggedWith restricts a query on shortens to the links that carry the tag
+// named name
+func taggedWith(name string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`id IN (SELECT shorten_tags.shorten_id FROM shorten_tags
+			JOIN tags ON tags.id = shorten_tags.tag_id WHERE tags.name = ?)`, name)
+	}
+}
+
+// TagRepository defines the data access interface for link tags
+type TagRepository interface {
+	Stats(ctx context.Context, scope models.LinkScope) ([]models.TagStats, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+// Stats totals the links and clicks of each tag over the links in scope,
+// busiest tag first. Tags none of those links carry are left out.
+func (r *tagRepository) Stats(ctx context.Context, scope models.LinkScope) ([]models.TagStats, error) {
+	links := r.db.Model(&models.Shorten{}).Scopes(inScope(scope)).Select("id", "click_count")
+
+	var stats []models.TagStats
+	result := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name, COUNT(links.id) AS links, COALESCE(SUM(links.click_count), 0) AS clicks").
+		Joins("JOIN shorten_tags ON shorten_tags.tag_id = tags.id").
+		Joins("JOIN (?) AS links ON links.id = shorten_tags.shorten_id", links).
+		Where("tags.workspace_id = ?", scope.WorkspaceID).
+		Group("tags.name").
+		Order("clicks DESC, tags.name").
+		Scan(&stats)
+	return stats, result.Error
+}
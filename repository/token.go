@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenRepository defines the data access interface for session tokens
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	FindRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uint64, at time.Time) (bool, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID uint64, at time.Time) error
+	RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	PurgeExpired(ctx context.Context, before time.Time) error
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository creates a new token repository
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{
+		db: db,
+	}
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *tokenRepository) FindRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken reports whether this call revoked the token, so two
+// concurrent refreshes cannot both rotate the same token
+func (r *tokenRepository) RevokeRefreshToken(ctx context.Context, id uint64, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", at).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	return r.db.WithContext(ctx).Save(token).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.RevokedToken{}).
+		Where("token_id = ?", tokenID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// PurgeExpired drops refresh tokens and revocations that can no longer matter
+func (r *tokenRepository) PurgeExpired(ctx context.Context, before time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", before).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error
+	})
+}
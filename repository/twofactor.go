@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository defines the data access interface for 2FA recovery codes
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID uint64, codes []models.RecoveryCode) error
+	Use(ctx context.Context, userID uint64, hash string, at time.Time) (bool, error)
+	DeleteForUser(ctx context.Context, userID uint64) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Replace drops every existing code of the user and stores codes instead
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID uint64, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use marks an unused code as used and reports whether one matched
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID uint64, hash string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		UpdateColumn("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *recoveryCodeRepository) DeleteForUser(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository defines the data access interface for local users
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	FindById(ctx context.Context, id uint64) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	List(ctx context.Context) ([]models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	TouchLastLogin(ctx context.Context, id uint64, at time.Time) error
+	AdvanceTOTPStep(ctx context.Context, id uint64, step int64) (bool, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	result := r.db.WithContext(ctx).Create(user)
+	return user, result.Error
+}
+
+func (r *userRepository) FindById(ctx context.Context, id uint64) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).First(&user, id)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Where("username = ?", username).First(&user)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) List(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	result := r.db.WithContext(ctx).Order("username asc").Find(&users)
+	return users, result.Error
+}
+
+func (r *userRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	result := r.db.WithContext(ctx).Save(user)
+	return user, result.Error
+}
+
+func (r *userRepository) TouchLastLogin(ctx context.Context, id uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", id).
+		UpdateColumn("last_login_at", at).Error
+}
+
+// AdvanceTOTPStep records step as the last accepted TOTP time step. It reports
+// false when that step (or a later one) was already used, which makes every
+// code single-use even under concurrent logins.
+func (r *userRepository) AdvanceTOTPStep(ctx context.Context, id uint64, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		UpdateColumn("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceRepository defines the data access interface for workspaces and
+// their members
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	Update(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	UpdateDomain(ctx context.Context, workspace *models.Workspace) ([]string, error)
+	FindById(ctx context.Context, id uint64) (*models.Workspace, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Workspace, error)
+	FindByDomain(ctx context.Context, domain string) (*models.Workspace, error)
+	List(ctx context.Context) ([]models.Workspace, error)
+	ListForUser(ctx context.Context, userID uint64) ([]models.Workspace, error)
+	AddMember(ctx context.Context, member *models.WorkspaceMember) error
+	UpdateMemberRole(ctx context.Context, workspaceID, userID uint64, role models.Role) (bool, error)
+	RemoveMember(ctx context.Context, workspaceID, userID uint64) (bool, error)
+	ListMembers(ctx context.Context, workspaceID uint64) ([]models.WorkspaceMemberData, error)
+	FindMember(ctx context.Context, workspaceID, userID uint64) (*models.WorkspaceMember, error)
+	FirstMembership(ctx context.Context, userID uint64) (*models.WorkspaceMember, error)
+	CountOwners(ctx context.Context, workspaceID uint64) (int64, error)
+	AdoptOrphans(ctx context.Context, workspaceID uint64) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository creates a new workspace repository
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{
+		db: db,
+	}
+}
+
+func (r *workspaceRepository) Create(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	result := r.db.WithContext(ctx).Create(workspace)
+	return workspace, result.Error
+}
+
+func (r *workspaceRepository) Update(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	result := r.db.WithContext(ctx).Save(workspace)
+	return workspace, result.Error
+}
+
+// maxDomainClashes bounds how many clashing codes UpdateDomain reports
+const maxDomainClashes = 10
+
+// UpdateDomain saves workspace and moves all of its links onto its domain in
+// one transaction. If links of other workspaces already use some of their
+// codes on that domain, nothing changes and those codes are returned.
+func (r *workspaceRepository) UpdateDomain(ctx context.Context, workspace *models.Workspace) ([]string, error) {
+	var domain string
+	if workspace.Domain != nil {
+		domain = *workspace.Domain
+	}
+
+	var clashes []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		codes := tx.Model(&models.Shorten{}).Where("workspace_id = ?", workspace.ID).Select("short_code")
+		if err := tx.Model(&models.Shorten{}).
+			Where("domain = ? AND workspace_id <> ? AND short_code IN (?)", domain, workspace.ID, codes).
+			Order("short_code").
+			Limit(maxDomainClashes).
+			Pluck("short_code", &clashes).Error; err != nil {
+			return err
+		}
+		if len(clashes) > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.Shorten{}).
+			Where("workspace_id = ?", workspace.ID).
+			UpdateColumn("domain", domain).Error; err != nil {
+			return err
+		}
+		return tx.Save(workspace).Error
+	})
+	return clashes, err
+}
+
+func (r *workspaceRepository) FindById(ctx context.Context, id uint64) (*models.Workspace, error) {
+	return r.findOne(r.db.WithContext(ctx).Where("id = ?", id))
+}
+
+func (r *workspaceRepository) FindBySlug(ctx context.Context, slug string) (*models.Workspace, error) {
+	return r.findOne(r.db.WithContext(ctx).Where("slug = ?", slug))
+}
+
+func (r *workspaceRepository) FindByDomain(ctx context.Context, domain string) (*models.Workspace, error) {
+	return r.findOne(r.db.WithContext(ctx).Where("domain = ?", domain))
+}
+
+// findOne returns the first workspace matching query, or nil if there is none
+func (r *workspaceRepository) findOne(query *gorm.DB) (*models.Workspace, error) {
+	var workspace models.Workspace
+	result := query.First(&workspace)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &workspace, nil
+}
+
+func (r *workspaceRepository) List(ctx context.Context) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	result := r.db.WithContext(ctx).Order("id").Find(&workspaces)
+	return workspaces, result.Error
+}
+
+// ListForUser returns the workspaces userID is a member of, oldest
+// membership first
+func (r *workspaceRepository) ListForUser(ctx context.Context, userID uint64) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	result := r.db.WithContext(ctx).
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspace_members.created_at, workspaces.id").
+		Find(&workspaces)
+	return workspaces, result.Error
+}
+
+// AddMember is a no-op when the user already is a member
+func (r *workspaceRepository) AddMember(ctx context.Context, member *models.WorkspaceMember) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(member).Error
+}
+
+func (r *workspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID uint64, role models.Role) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		UpdateColumn("role", role)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&models.WorkspaceMember{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID uint64) ([]models.WorkspaceMemberData, error) {
+	var memberships []models.WorkspaceMember
+	result := r.db.WithContext(ctx).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at, user_id").
+		Find(&memberships)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	userIDs := make([]uint64, len(memberships))
+	for i, membership := range memberships {
+		userIDs[i] = membership.UserID
+	}
+
+	var users []models.User
+	if len(userIDs) > 0 {
+		if err := r.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	usersByID := make(map[uint64]*models.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	members := make([]models.WorkspaceMemberData, 0, len(memberships))
+	for _, membership := range memberships {
+		if user, ok := usersByID[membership.UserID]; ok {
+			members = append(members, models.WorkspaceMemberData{User: user, Role: membership.Role})
+		}
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) FindMember(ctx context.Context, workspaceID, userID uint64) (*models.WorkspaceMember, error) {
+	return r.findMember(r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID))
+}
+
+// FirstMembership returns the oldest membership of userID, or nil if the
+// user belongs to no workspace
+func (r *workspaceRepository) FirstMembership(ctx context.Context, userID uint64) (*models.WorkspaceMember, error) {
+	return r.findMember(r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at, workspace_id"))
+}
+
+func (r *workspaceRepository) findMember(query *gorm.DB) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	result := query.First(&member)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+func (r *workspaceRepository) CountOwners(ctx context.Context, workspaceID uint64) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).
+		Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, models.RoleOwner).
+		Count(&count)
+	return count, result.Error
+}
+
+// AdoptOrphans moves links and API keys without a workspace into
+// workspaceID and makes every user without a membership a member of it
+func (r *workspaceRepository) AdoptOrphans(ctx context.Context, workspaceID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Shorten{}).
+			Where("workspace_id = 0").
+			UpdateColumn("workspace_id", workspaceID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.APIKey{}).
+			Where("workspace_id = 0").
+			UpdateColumn("workspace_id", workspaceID).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`INSERT INTO workspace_members (workspace_id, user_id, created_at)
+			SELECT ?, users.id, ? FROM users
+			WHERE NOT EXISTS (SELECT 1 FROM workspace_members WHERE workspace_members.user_id = users.id)`,
+			workspaceID, time.Now()).Error
+	})
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAnalyticsRoutes(rg *gin.RouterGroup, service services.AnalyticsService) {
+	analyticsHandlers := handlers.NewAnalyticsHandler(service)
+	read := middleware.RequireScope(models.ScopeAnalyticsRead)
+
+	urls := rg.Group("/urls", read)
+	{
+
+		urls.GET("/:code/stats", analyticsHandlers.GetStats)
+
+	}
+
+	rg.GET("/shorten/:code/stats/timeseries", read, analyticsHandlers.GetTimeseries)
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAPIKeyRoutes(rg *gin.RouterGroup, service services.APIKeyService) {
+	apiKeyHandlers := handlers.NewAPIKeyHandler(service)
+	keys := rg.Group("/keys", middleware.RequireScope(models.ScopeKeysAdmin))
+	{
+
+		keys.GET("", apiKeyHandlers.List)
+		keys.POST("", apiKeyHandlers.Create)
+		keys.DELETE("/:id", apiKeyHandlers.Revoke)
+
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAuthRoutes registers session endpoints. Login and refresh are
+// public; logout and me run behind authenticate.
+func RegisterAuthRoutes(rg *gin.RouterGroup, service services.AuthService, authenticate gin.HandlerFunc) {
+	authHandlers := handlers.NewAuthHandler(service)
+	auth := rg.Group("/auth")
+	{
+
+		auth.POST("/login", authHandlers.Login)
+		auth.POST("/refresh", authHandlers.Refresh)
+		auth.POST("/logout", authenticate, authHandlers.Logout)
+		auth.GET("/me", authenticate, authHandlers.Me)
+
+	}
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
 	"portus/services"
 
 	"github.com/gin-gonic/gin"
@@ -9,7 +11,7 @@ import (
 
 func RegisterConfigRoutes(rg *gin.RouterGroup, service services.ConfigService) {
 	configHandlers := handlers.NewConfigHandler(service)
-	configs := rg.Group("/config")
+	configs := rg.Group("/config", middleware.RequireScope(models.ScopeConfigAdmin))
 	{
 
 		configs.GET("", configHandlers.GetConfig)
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterExportRoutes(rg *gin.RouterGroup, service services.ExportService) {
+	exportHandlers := handlers.NewExportHandler(service)
+
+	rg.GET("/export", middleware.RequireScope(models.ScopeLinksRead), exportHandlers.Export)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterImportRoutes(rg *gin.RouterGroup, service services.ImportService) {
+	importHandlers := handlers.NewImportHandler(service)
+	imports := rg.Group("/imports")
+	{
+
+		imports.POST("", middleware.RequireScope(models.ScopeLinksWrite), importHandlers.Start)
+		imports.GET("/:id", middleware.RequireScope(models.ScopeLinksRead), importHandlers.Get)
+
+	}
+}
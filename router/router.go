@@ -3,9 +3,11 @@ package router
 
 import (
 	"context"
+	"portus/middleware"
 	"portus/repository"
 	"portus/services"
 	"portus/utils"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,10 +15,25 @@ import (
 )
 
 func Setup(ctx context.Context, db *gorm.DB, configService services.ConfigService) *gin.Engine {
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Logger(), middleware.Recovery())
 	log := utils.LoggerFromContext(ctx)
 
 	appConfig := configService.GetConfig()
+
+	// Only believe X-Forwarded-For from our own proxies, otherwise visitors
+	// could pick the IP that lockouts and geo rules see
+	trustedProxies := []string(nil)
+	if appConfig.HTTP.ProxyEnabled {
+		trustedProxies = appConfig.HTTP.TrustedProxies
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid http.trustedProxies")
+	}
+	log.Info().
+		Bool("ProxyEnabled", appConfig.HTTP.ProxyEnabled).
+		Strs("TrustedProxies", trustedProxies).
+		Msg("Trusted proxies set.")
 	// CORS config
 	config := cors.DefaultConfig()
 	config.AllowOrigins = appConfig.Auth.AllowedOrigins
@@ -26,7 +43,7 @@ func Setup(ctx context.Context, db *gorm.DB, configService services.ConfigServic
 		Msg("Allowed Origins set.")
 
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Authorization", "Content-Type"}
+	config.AllowHeaders = []string{"Origin", "Authorization", "Content-Type", "X-API-Key", "X-Workspace-ID"}
 	r.Use(cors.New(config))
 
 	// Setup API v1 routes
@@ -36,12 +53,69 @@ func Setup(ctx context.Context, db *gorm.DB, configService services.ConfigServic
 	healthService := services.NewHealthService(db)
 
 	shortenRepo := repository.NewShortenRepository(db)
-	shortenService := services.NewShortenService(shortenRepo, configService.GetConfig().App.AppURL)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo, configService)
+	defaultWorkspace, err := workspaceService.EnsureDefaultWorkspace(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up the default workspace")
+	}
+
+	analyticsService := services.NewAnalyticsService(analyticsRepo, shortenRepo,
+		appConfig.Analytics.IPHashSalt, appConfig.Analytics.TopReferrers)
+	geoLocator, err := services.NewGeoLocator(appConfig.GeoIP.DatabasePath)
+	if err != nil {
+		log.Fatal().Err(err).Str("path", appConfig.GeoIP.DatabasePath).Msg("Failed to open GeoIP database")
+	}
+	shortenService := services.NewShortenService(shortenRepo, workspaceRepo, analyticsService, configService, geoLocator)
+	importService := services.NewImportService(repository.NewImportRepository(db), shortenService)
+	if err := importService.FailInterrupted(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to mark interrupted imports as failed")
+	}
+	exportService := services.NewExportService(shortenRepo, analyticsRepo, configService)
+	tagService := services.NewTagService(repository.NewTagRepository(db))
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+
+	if err := apiKeyService.EnsureBootstrapKey(ctx, defaultWorkspace.ID); err != nil {
+		log.Error().Err(err).Msg("Failed to create bootstrap API key")
+	}
 
-	// Register all routes
-	RegisterConfigRoutes(v1, configService)
+	tokenRepo := repository.NewTokenRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	userService := services.NewUserService(userRepo, workspaceRepo)
+	twoFactorService := services.NewTwoFactorService(userRepo, recoveryCodeRepo, configService)
+	authService := services.NewAuthService(userRepo, tokenRepo, twoFactorService, configService)
+
+	// Roll raw clicks into hourly/daily buckets in the background
+	clickAggregator := services.NewClickAggregator(analyticsRepo)
+	go clickAggregator.Start(ctx, time.Duration(appConfig.Analytics.RollupInterval)*time.Minute)
+
+	authenticate := middleware.Authenticate(apiKeyService, authService, workspaceService)
+
+	// Public routes
 	RegisterHealthRoutes(v1, healthService)
-	RegisterShortenRoutes(v1, shortenService)
+	RegisterAuthRoutes(v1, authService, authenticate)
+	RegisterTwoFactorRoutes(v1, twoFactorService, authenticate)
+
+	// Management routes require an API key or a user access token
+	protected := v1.Group("", authenticate)
+
+	RegisterConfigRoutes(protected, configService)
+	RegisterShortenRoutes(protected, shortenService)
+	RegisterImportRoutes(protected, importService)
+	RegisterExportRoutes(protected, exportService)
+	RegisterTagRoutes(protected, tagService)
+	RegisterAnalyticsRoutes(protected, analyticsService)
+	RegisterAPIKeyRoutes(protected, apiKeyService)
+	RegisterUserRoutes(protected, userService)
+	RegisterWorkspaceRoutes(protected, workspaceService)
+
+	// Short links resolve at the root, e.g. GET /abc123
+	RegisterRedirectRoutes(r, shortenService)
 
 	return r
 }
@@ -2,6 +2,8 @@ package router
 
 import (
 	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
 	"portus/services"
 
 	"github.com/gin-gonic/gin"
@@ -12,11 +14,30 @@ func RegisterShortenRoutes(rg *gin.RouterGroup, service services.ShortenService)
 	shorts := rg.Group("/shorten")
 	{
 
-		shorts.POST("", shortenHandlers.Create)
-		shorts.POST("lookup", shortenHandlers.GetByOriginalURL)
-		shorts.PUT("/:code", shortenHandlers.Update)
-		shorts.DELETE("/:code", shortenHandlers.Delete)
-		shorts.GET("/:code", shortenHandlers.Redirect)
+		read := middleware.RequireScope(models.ScopeLinksRead)
+		write := middleware.RequireScope(models.ScopeLinksWrite)
+
+		shorts.GET("", read, shortenHandlers.List)
+		shorts.POST("", write, shortenHandlers.Create)
+		shorts.POST("batch", write, shortenHandlers.CreateBatch)
+		shorts.POST("bulk", write, shortenHandlers.Bulk)
+		shorts.POST("lookup", write, shortenHandlers.GetByOriginalURL)
+		shorts.PUT("/:code", write, shortenHandlers.Update)
+		shorts.DELETE("/:code", write, shortenHandlers.Delete)
 
 	}
 }
+
+// RegisterRedirectRoutes serves short links at the root of the host. Paths
+// listed in constants.ReservedShortCodes must be registered as static routes
+// (or left unused) so they are never mistaken for a short code.
+func RegisterRedirectRoutes(r *gin.Engine, service services.ShortenService) {
+	shortenHandlers := handlers.NewShortenHandler(service)
+
+	r.GET("/:code", shortenHandlers.Redirect)
+	r.POST("/:code", shortenHandlers.Unlock)
+
+	// Trailing paths only resolve for links with passthrough
+	r.GET("/:code/*path", shortenHandlers.Redirect)
+	r.POST("/:code/*path", shortenHandlers.Unlock)
+}
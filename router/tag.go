@@ -0,0 +1,16 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTagRoutes(rg *gin.RouterGroup, service services.TagService) {
+	tagHandlers := handlers.NewTagHandler(service)
+
+	rg.GET("/tags", middleware.RequireScope(models.ScopeLinksRead), tagHandlers.List)
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTwoFactorRoutes registers TOTP enrollment endpoints. They require a
+// user session but no scope, so sessions restricted by auth.enable2FA can use them.
+func RegisterTwoFactorRoutes(rg *gin.RouterGroup, service services.TwoFactorService, authenticate gin.HandlerFunc) {
+	twoFactorHandlers := handlers.NewTwoFactorHandler(service)
+	twoFactor := rg.Group("/auth/2fa", authenticate)
+	{
+
+		twoFactor.POST("/setup", twoFactorHandlers.Setup)
+		twoFactor.POST("/enable", twoFactorHandlers.Enable)
+		twoFactor.POST("/disable", twoFactorHandlers.Disable)
+		twoFactor.POST("/recovery-codes", twoFactorHandlers.RegenerateRecoveryCodes)
+
+	}
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterUserRoutes(rg *gin.RouterGroup, service services.UserService) {
+	userHandlers := handlers.NewUserHandler(service)
+	users := rg.Group("/users", middleware.RequireScope(models.ScopeUsersAdmin))
+	{
+
+		users.GET("", userHandlers.List)
+		users.POST("", userHandlers.Create)
+
+	}
+}
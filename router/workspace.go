@@ -0,0 +1,30 @@
+package router
+
+import (
+	"portus/handlers"
+	"portus/middleware"
+	"portus/models"
+	"portus/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWorkspaceRoutes(rg *gin.RouterGroup, service services.WorkspaceService) {
+	workspaceHandlers := handlers.NewWorkspaceHandler(service)
+	requireAdmin := middleware.RequireScope(models.ScopeWorkspacesAdmin)
+	// Owners manage their own workspace; the service checks which one
+	requireManage := middleware.RequireAnyScope(models.ScopeWorkspacesAdmin, models.ScopeMembersAdmin)
+	workspaces := rg.Group("/workspaces")
+	{
+
+		workspaces.GET("", workspaceHandlers.List)
+		workspaces.GET("/:id", workspaceHandlers.Get)
+		workspaces.POST("", requireAdmin, workspaceHandlers.Create)
+		workspaces.PUT("/:id", requireManage, workspaceHandlers.Update)
+		workspaces.GET("/:id/members", requireManage, workspaceHandlers.ListMembers)
+		workspaces.POST("/:id/members", requireManage, workspaceHandlers.AddMember)
+		workspaces.PUT("/:id/members/:userId", requireManage, workspaceHandlers.UpdateMember)
+		workspaces.DELETE("/:id/members/:userId", requireManage, workspaceHandlers.RemoveMember)
+
+	}
+}
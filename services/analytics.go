@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// directReferrer labels clicks that arrived without a Referer header
+const directReferrer = "direct"
+
+// maxTimeseriesPoints bounds the number of buckets a single query may return
+const maxTimeseriesPoints = 5000
+
+// ErrInvalidTimeRange is returned when a timeseries range is empty or too wide
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// AnalyticsService provides methods to record and report link clicks
+type AnalyticsService interface {
+	RecordClick(ctx context.Context, shorten *models.Shorten, visitor models.Visitor, destinationID uint64) error
+	GetStats(ctx context.Context, code string) (*models.ShortenStats, error)
+	GetTimeseries(ctx context.Context, code string, interval models.RollupInterval, from, to time.Time) (*models.ShortenTimeseries, error)
+}
+
+type analyticsService struct {
+	repo         repository.AnalyticsRepository
+	shortenRepo  repository.ShortenRepository
+	ipHashSalt   []byte
+	topReferrers int
+}
+
+// NewAnalyticsService creates a new analytics service. Visitor IPs are never
+// stored in clear; they are hashed with ipHashSalt. When no salt is configured
+// a random one is used, so hashes are only comparable within a single run.
+func NewAnalyticsService(repo repository.AnalyticsRepository, shortenRepo repository.ShortenRepository, ipHashSalt string, topReferrers int) AnalyticsService {
+	salt := []byte(ipHashSalt)
+	if len(salt) == 0 {
+		log.Warn().Msg("analytics.ipHashSalt is not set, using a random salt for this run")
+		salt = make([]byte, 32)
+		rand.Read(salt)
+	}
+
+	return &analyticsService{
+		repo:         repo,
+		shortenRepo:  shortenRepo,
+		ipHashSalt:   salt,
+		topReferrers: topReferrers,
+	}
+}
+
+func (s *analyticsService) RecordClick(ctx context.Context, shorten *models.Shorten, visitor models.Visitor, destinationID uint64) error {
+	click := &models.Click{
+		ShortenID:      shorten.ID,
+		ShortCode:      shorten.ShortCode,
+		Timestamp:      time.Now(),
+		Referrer:       utils.Truncate(visitor.Referrer, 2048),
+		UserAgent:      utils.Truncate(visitor.UserAgent, 512),
+		IPHash:         s.hashIP(visitor.IP),
+		AcceptLanguage: utils.Truncate(visitor.AcceptLanguage, 256),
+		DestinationID:  destinationID,
+	}
+
+	return s.repo.RecordClick(ctx, click)
+}
+
+func (s *analyticsService) GetStats(ctx context.Context, code string) (*models.ShortenStats, error) {
+	shorten, err := s.shortenRepo.FindScopedByCode(ctx, code, readScope(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if shorten == nil {
+		return nil, ErrShortURLNotFound
+	}
+
+	lastAccessed, err := s.repo.LastClickAt(ctx, shorten.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := s.repo.TopReferrers(ctx, shorten.ID, s.topReferrers)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range referrers {
+		if referrers[i].Referrer == "" {
+			referrers[i].Referrer = directReferrer
+		}
+	}
+
+	variants, err := s.variants(ctx, shorten)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ShortenStats{
+		ShortCode:    shorten.ShortCode,
+		Clicks:       shorten.ClickCount,
+		CreatedAt:    shorten.CreatedAt,
+		LastAccessed: lastAccessed,
+		TopReferrers: referrers,
+		Variants:     variants,
+	}, nil
+}
+
+// variants counts the clicks of each current destination of a split link.
+// Clicks of removed destinations are left out.
+func (s *analyticsService) variants(ctx context.Context, shorten *models.Shorten) ([]models.VariantStats, error) {
+	if len(shorten.Destinations) == 0 {
+		return nil, nil
+	}
+
+	clicks, err := s.repo.ClicksByDestination(ctx, shorten.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]models.VariantStats, len(shorten.Destinations))
+	for i, destination := range shorten.Destinations {
+		variants[i] = models.VariantStats{
+			DestinationID: destination.ID,
+			URL:           destination.URL,
+			Weight:        destination.Weight,
+			Clicks:        clicks[destination.ID],
+		}
+	}
+	return variants, nil
+}
+
+// GetTimeseries returns one point per bucket in [from, to), including empty
+// buckets. Data comes from the rollup tables, so the newest bucket lags behind
+// the raw clicks by up to one aggregation interval.
+func (s *analyticsService) GetTimeseries(ctx context.Context, code string, interval models.RollupInterval, from, to time.Time) (*models.ShortenTimeseries, error) {
+	step := interval.Duration()
+	from = from.UTC().Truncate(step)
+	to = to.UTC()
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTimeRange)
+	}
+	if to.Sub(from)/step > maxTimeseriesPoints {
+		return nil, fmt.Errorf("%w: more than %d %s buckets requested", ErrInvalidTimeRange, maxTimeseriesPoints, interval)
+	}
+
+	shorten, err := s.shortenRepo.FindScopedByCode(ctx, code, readScope(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if shorten == nil {
+		return nil, ErrShortURLNotFound
+	}
+
+	rollups, err := s.repo.GetRollups(ctx, shorten.ID, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	clicks := make(map[time.Time]int64, len(rollups))
+	for _, rollup := range rollups {
+		clicks[rollup.BucketStart.UTC()] = rollup.Clicks
+	}
+
+	points := make([]models.TimeseriesPoint, 0, to.Sub(from)/step+1)
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(step) {
+		points = append(points, models.TimeseriesPoint{
+			Timestamp: bucket,
+			Clicks:    clicks[bucket],
+		})
+	}
+
+	return &models.ShortenTimeseries{
+		ShortCode: shorten.ShortCode,
+		Interval:  interval,
+		From:      from,
+		To:        to,
+		Points:    points,
+	}, nil
+}
+
+// hashIP returns a keyed hash of the visitor IP so repeat visits can be
+// correlated without storing the address itself
+func (s *analyticsService) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, s.ipHashSalt)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Errors returned by APIKeyService
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidAPIKey  = errors.New("invalid or revoked API key")
+	ErrUnknownScope   = errors.New("unknown scope")
+	ErrScopeNotHeld   = errors.New("cannot grant a scope you do not hold")
+)
+
+const (
+	// apiKeyPrefix marks Portus API keys so they are easy to spot in secret scanners
+	apiKeyPrefix = "pk_"
+	// secretBytes is the amount of randomness in API keys and refresh tokens
+	secretBytes = 24
+	// apiKeyDisplayLength is how much of the key is kept in clear for identification
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+)
+
+// APIKeyService provides methods to manage and verify API keys
+type APIKeyService interface {
+	Create(ctx context.Context, req models.CreateAPIKeyRequest) (*models.APIKeyData, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id uint64) error
+	Authenticate(ctx context.Context, rawKey string) (*models.Principal, error)
+	EnsureBootstrapKey(ctx context.Context, workspaceID uint64) error
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		repo: repo,
+	}
+}
+
+// Create issues a key bound to the caller's workspace. Keys can only hold
+// scopes the caller holds, so roles cannot be escalated through keys.
+func (s *apiKeyService) Create(ctx context.Context, req models.CreateAPIKeyRequest) (*models.APIKeyData, error) {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil || principal.WorkspaceID == 0 {
+		return nil, ErrNoWorkspace
+	}
+
+	for _, scope := range req.Scopes {
+		if scope.IsValid() && !principal.HasScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrScopeNotHeld, scope)
+		}
+	}
+
+	return s.create(ctx, principal.WorkspaceID, req)
+}
+
+func (s *apiKeyService) create(ctx context.Context, workspaceID uint64, req models.CreateAPIKeyRequest) (*models.APIKeyData, error) {
+	for _, scope := range req.Scopes {
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownScope, scope)
+		}
+	}
+
+	rawKey, err := generateSecret(apiKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{
+		Name:        req.Name,
+		WorkspaceID: workspaceID,
+		Prefix:      rawKey[:apiKeyDisplayLength],
+		KeyHash:     hashSecret(rawKey),
+		Scopes:      req.Scopes,
+		CreatedAt:   time.Now(),
+	}
+
+	if req.ExpiresAfter > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresAfter)
+		key.ExpiresAt = &expiresAt
+	}
+
+	newKey, err := s.repo.Create(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyData{
+		APIKey: newKey,
+		Key:    rawKey,
+	}, nil
+}
+
+// List returns the keys of the caller's workspace
+func (s *apiKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil {
+		return []models.APIKey{}, nil
+	}
+	return s.repo.List(ctx, principal.WorkspaceID)
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id uint64) error {
+	key, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Keys of other workspaces are reported as missing
+	principal := utils.PrincipalFromContext(ctx)
+	if key == nil || principal == nil || key.WorkspaceID != principal.WorkspaceID {
+		return ErrAPIKeyNotFound
+	}
+
+	return s.repo.Revoke(ctx, id, time.Now())
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.Principal, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.FindByHash(ctx, hashSecret(rawKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || !key.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Usage tracking is best effort and must not slow the request down
+	go func(ctx context.Context) {
+		log := utils.LoggerFromContext(ctx)
+		if err := s.repo.TouchLastUsed(ctx, key.ID, time.Now()); err != nil {
+			log.Warn().Err(err).Uint64("apiKeyId", key.ID).Msg("Failed to update API key usage")
+		}
+	}(context.WithoutCancel(ctx))
+
+	return &models.Principal{
+		APIKeyID:    &key.ID,
+		Name:        key.Name,
+		Scopes:      key.Scopes,
+		WorkspaceID: key.WorkspaceID,
+	}, nil
+}
+
+// EnsureBootstrapKey creates a key holding every scope in workspaceID when
+// no keys exist yet, so a fresh install can be administered. The plaintext
+// key is printed once to stderr and never logged; the log only carries its
+// prefix, as log output is often shipped and kept elsewhere.
+func (s *apiKeyService) EnsureBootstrapKey(ctx context.Context, workspaceID uint64) error {
+	log := utils.LoggerFromContext(ctx)
+
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	data, err := s.create(ctx, workspaceID, models.CreateAPIKeyRequest{
+		Name:   "bootstrap",
+		Scopes: models.AllScopes,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nBootstrap API key (shown only once): %s\n\n", data.Key)
+	log.Warn().
+		Str("prefix", data.APIKey.Prefix).
+		Msg("No API keys found, created a bootstrap key with all scopes and printed it to stderr. Store it now and revoke it once you have created your own keys")
+	return nil
+}
+
+// generateSecret returns a new random credential in its plaintext form
+func generateSecret(prefix string) (string, error) {
+	randomBytes := make([]byte, secretBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// hashSecret derives the value stored for a generated credential. Secrets
+// carry 192 bits of randomness, so a fast hash is sufficient and allows an
+// indexed lookup.
+func hashSecret(secret string) string {
+	sum := sha3.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
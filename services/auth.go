@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Errors returned by AuthService
+var (
+	ErrLocalAuthDisabled  = errors.New("local authentication is disabled")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrNotUserSession     = errors.New("credential is not a user session")
+)
+
+const (
+	// tokenIssuer is set on and required of every access token
+	tokenIssuer = "portus"
+	// refreshTokenPrefix marks Portus refresh tokens
+	refreshTokenPrefix = "rt_"
+)
+
+// dummyPasswordHash is compared against when a username does not exist, so
+// unknown and known users take the same time to reject
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("portus-dummy-password"), bcrypt.DefaultCost)
+
+// AuthService provides methods for local user sessions
+type AuthService interface {
+	Login(ctx context.Context, req models.LoginRequest) (*models.TokenData, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenData, error)
+	Logout(ctx context.Context, principal *models.Principal, req models.LogoutRequest) error
+	Authenticate(ctx context.Context, accessToken string) (*models.Principal, error)
+}
+
+type authService struct {
+	users          repository.UserRepository
+	tokens         repository.TokenRepository
+	twoFactor      TwoFactorService
+	configService  ConfigService
+	fallbackSecret []byte
+}
+
+// accessClaims are the claims of a user access token
+type accessClaims struct {
+	jwt.RegisteredClaims
+
+	// TwoFactor is set when the session was established with a second factor
+	TwoFactor bool `json:"mfa,omitempty"`
+}
+
+// NewAuthService creates a new auth service. Access tokens are signed with
+// auth.jwtSecret; when it is not configured a random secret is used, so
+// sessions do not survive a restart.
+func NewAuthService(users repository.UserRepository, tokens repository.TokenRepository, twoFactor TwoFactorService, configService ConfigService) AuthService {
+	fallbackSecret := make([]byte, 32)
+	rand.Read(fallbackSecret)
+
+	if configService.GetConfig().Auth.JWTSecret == "" {
+		log.Warn().Msg("auth.jwtSecret is not set, using a random secret for this run")
+	}
+
+	return &authService{
+		users:          users,
+		tokens:         tokens,
+		twoFactor:      twoFactor,
+		configService:  configService,
+		fallbackSecret: fallbackSecret,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, req models.LoginRequest) (*models.TokenData, error) {
+	if !s.configService.GetConfig().Auth.EnableLocal {
+		return nil, ErrLocalAuthDisabled
+	}
+
+	user, err := s.users.FindByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+
+	twoFactor := false
+	if user.TOTPEnabled {
+		if err := s.twoFactor.Verify(ctx, user, req.TOTPCode, req.RecoveryCode); err != nil {
+			return nil, err
+		}
+		twoFactor = true
+	}
+
+	tokens, err := s.issueTokens(ctx, user, twoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.users.TouchLastLogin(ctx, user.ID, now); err != nil {
+		log := utils.LoggerFromContext(ctx)
+		log.Warn().Err(err).Uint64("userId", user.ID).Msg("Failed to record last login")
+	}
+	user.LastLoginAt = &now
+
+	return tokens, nil
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already
+// rotated revokes every session of its user, since it has likely leaked.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.TokenData, error) {
+	if !s.configService.GetConfig().Auth.EnableLocal {
+		return nil, ErrLocalAuthDisabled
+	}
+
+	stored, err := s.tokens.FindRefreshToken(ctx, hashSecret(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil || stored.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	if stored.RevokedAt != nil {
+		log := utils.LoggerFromContext(ctx)
+		log.Warn().Uint64("userId", stored.UserID).Msg("Revoked refresh token reused, revoking all sessions")
+		if err := s.tokens.RevokeUserRefreshTokens(ctx, stored.UserID, time.Now()); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.tokens.RevokeRefreshToken(ctx, stored.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// Lost a race against a concurrent refresh of the same token
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.users.FindById(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.Disabled {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(ctx, user, stored.TwoFactor)
+}
+
+func (s *authService) Logout(ctx context.Context, principal *models.Principal, req models.LogoutRequest) error {
+	log := utils.LoggerFromContext(ctx)
+
+	if principal == nil || principal.UserID == nil {
+		return ErrNotUserSession
+	}
+
+	if err := s.tokens.RevokeAccessToken(ctx, &models.RevokedToken{
+		TokenID:   principal.TokenID,
+		ExpiresAt: principal.TokenExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	if req.AllSessions {
+		if err := s.tokens.RevokeUserRefreshTokens(ctx, *principal.UserID, time.Now()); err != nil {
+			return err
+		}
+	} else if req.RefreshToken != "" {
+		stored, err := s.tokens.FindRefreshToken(ctx, hashSecret(req.RefreshToken))
+		if err != nil {
+			return err
+		}
+
+		// Never let one user revoke another user's session
+		if stored != nil && stored.UserID == *principal.UserID {
+			if _, err := s.tokens.RevokeRefreshToken(ctx, stored.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.tokens.PurgeExpired(ctx, time.Now()); err != nil {
+		log.Warn().Err(err).Msg("Failed to purge expired tokens")
+	}
+
+	return nil
+}
+
+func (s *authService) Authenticate(ctx context.Context, accessToken string) (*models.Principal, error) {
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.signingKey(), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	revoked, err := s.tokens.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Load the user on every request so disabling an account or changing its
+	// permissions takes effect immediately
+	user, err := s.users.FindById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.Disabled {
+		return nil, ErrInvalidToken
+	}
+
+	principal := &models.Principal{
+		UserID:         &user.ID,
+		Name:           user.Username,
+		Scopes:         user.Scopes(),
+		TokenID:        claims.ID,
+		TokenExpiresAt: claims.ExpiresAt.Time,
+	}
+
+	// Sessions without a second factor are limited to 2FA enrollment while
+	// the administrator enforces it
+	if s.configService.GetConfig().Auth.Enable2FA && !claims.TwoFactor {
+		principal.Scopes = nil
+		principal.TwoFactorSetupRequired = true
+	}
+
+	return principal, nil
+}
+
+// issueTokens creates a signed access token and a stored refresh token
+func (s *authService) issueTokens(ctx context.Context, user *models.User, twoFactor bool) (*models.TokenData, error) {
+	authConfig := s.configService.GetConfig().Auth
+	now := time.Now()
+
+	expiresAt := now.Add(time.Duration(authConfig.TokenExpiration) * time.Hour)
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatUint(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		TwoFactor: twoFactor,
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.signingKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := generateSecret(refreshTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokens.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashSecret(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, authConfig.SessionTimeout),
+		TwoFactor: twoFactor,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenData{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+		User:         user,
+
+		TwoFactorSetupRequired: authConfig.Enable2FA && !twoFactor,
+	}, nil
+}
+
+// signingKey reads the secret on every call so it can be rotated via config
+func (s *authService) signingKey() []byte {
+	if secret := s.configService.GetConfig().Auth.JWTSecret; secret != "" {
+		return []byte(secret)
+	}
+	return s.fallbackSecret
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"testing"
+)
+
+// fakeShortenRepository keeps links in memory. Methods CreateBatch does not
+// use panic through the nil embedded interface.
+type fakeShortenRepository struct {
+	repository.ShortenRepository
+
+	// existing holds the codes already stored
+	existing map[string]bool
+	// raced holds codes another request stores between the check and Create
+	raced map[string]bool
+	// createAllErr is returned by CreateAll
+	createAllErr error
+
+	created        []string
+	createAllCalls int
+}
+
+func (r *fakeShortenRepository) FindByCode(_ context.Context, _, code string) (*models.Shorten, error) {
+	if r.existing[code] {
+		return &models.Shorten{ShortCode: code}, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeShortenRepository) Create(_ context.Context, shorten *models.Shorten) (*models.Shorten, error) {
+	if r.raced[shorten.ShortCode] {
+		return nil, repository.ErrDuplicateShortCode
+	}
+	r.created = append(r.created, shorten.ShortCode)
+	return shorten, nil
+}
+
+func (r *fakeShortenRepository) CreateAll(_ context.Context, shortens []*models.Shorten) error {
+	r.createAllCalls++
+	if r.createAllErr != nil {
+		return r.createAllErr
+	}
+	for _, shorten := range shortens {
+		r.created = append(r.created, shorten.ShortCode)
+	}
+	return nil
+}
+
+type fakeWorkspaceRepository struct {
+	repository.WorkspaceRepository
+}
+
+func (fakeWorkspaceRepository) FindById(_ context.Context, id uint64) (*models.Workspace, error) {
+	return &models.Workspace{ID: id}, nil
+}
+
+type fakeConfigService struct {
+	ConfigService
+}
+
+func (fakeConfigService) GetConfig() *models.Configuration {
+	var cfg models.Configuration
+	cfg.App.AppURL = "https://sho.rt"
+	return &cfg
+}
+
+func TestCreateBatch(t *testing.T) {
+	link := func(code string) models.ShortenRequest {
+		return models.ShortenRequest{OriginalURL: "https://example.com/" + code, CustomCode: code}
+	}
+
+	tests := []struct {
+		name         string
+		atomic       bool
+		reqs         []models.ShortenRequest
+		existing     []string
+		raced        []string
+		createAllErr error
+		// wantErrs holds the error expected for each item, nil when it is
+		// created
+		wantErrs      []error
+		wantCreated   int
+		wantCreateAll int
+	}{
+		{
+			name:        "partial batch creates the valid items",
+			reqs:        []models.ShortenRequest{link("spring"), link("docs"), link("taken"), link("bad code")},
+			existing:    []string{"taken"},
+			wantErrs:    []error{nil, ErrShortCodeReserved, ErrShortCodeExists, ErrInvalidShortCode},
+			wantCreated: 1,
+		},
+		{
+			name:        "partial batch rejects a code repeated within it",
+			reqs:        []models.ShortenRequest{link("spring"), link("spring")},
+			wantErrs:    []error{nil, ErrShortCodeExists},
+			wantCreated: 1,
+		},
+		{
+			name:        "partial batch reports a code taken concurrently",
+			reqs:        []models.ShortenRequest{link("spring"), link("summer")},
+			raced:       []string{"summer"},
+			wantErrs:    []error{nil, ErrShortCodeExists},
+			wantCreated: 1,
+		},
+		{
+			name:        "partial batch generates codes",
+			reqs:        []models.ShortenRequest{{OriginalURL: "https://example.com/a"}, {OriginalURL: "https://example.com/b"}},
+			wantErrs:    []error{nil, nil},
+			wantCreated: 2,
+		},
+		{
+			name:          "atomic batch creates everything at once",
+			atomic:        true,
+			reqs:          []models.ShortenRequest{link("spring"), {OriginalURL: "https://example.com/b"}},
+			wantErrs:      []error{nil, nil},
+			wantCreated:   2,
+			wantCreateAll: 1,
+		},
+		{
+			name:     "atomic batch aborts when an item is invalid",
+			atomic:   true,
+			reqs:     []models.ShortenRequest{link("spring"), link("taken"), link("summer")},
+			existing: []string{"taken"},
+			wantErrs: []error{ErrBatchAborted, ErrShortCodeExists, ErrBatchAborted},
+		},
+		{
+			name:     "atomic batch aborts on a code repeated within it",
+			atomic:   true,
+			reqs:     []models.ShortenRequest{link("spring"), link("spring")},
+			wantErrs: []error{ErrBatchAborted, ErrShortCodeExists},
+		},
+		{
+			name:          "atomic batch reports a code taken concurrently for every item",
+			atomic:        true,
+			reqs:          []models.ShortenRequest{link("spring"), link("summer")},
+			createAllErr:  repository.ErrDuplicateShortCode,
+			wantErrs:      []error{ErrShortCodeExists, ErrShortCodeExists},
+			wantCreateAll: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeShortenRepository{
+				existing:     make(map[string]bool),
+				raced:        make(map[string]bool),
+				createAllErr: tt.createAllErr,
+			}
+			for _, code := range tt.existing {
+				repo.existing[code] = true
+			}
+			for _, code := range tt.raced {
+				repo.raced[code] = true
+			}
+			s := &shortenService{repo: repo, workspaces: fakeWorkspaceRepository{}, configService: fakeConfigService{}}
+			ctx := utils.WithPrincipal(context.Background(), &models.Principal{Name: "tester", WorkspaceID: 1})
+
+			results, err := s.CreateBatch(ctx, tt.reqs, tt.atomic)
+			if err != nil {
+				t.Fatalf("CreateBatch() error = %v", err)
+			}
+			if len(results) != len(tt.reqs) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.reqs))
+			}
+
+			for i, result := range results {
+				want := tt.wantErrs[i]
+				if !errors.Is(result.Err, want) || (want == nil && result.Err != nil) {
+					t.Errorf("item %d: error = %v, want %v", i, result.Err, want)
+				}
+				if (result.Data != nil) != (want == nil) {
+					t.Errorf("item %d: data = %v, want data only without an error", i, result.Data)
+				}
+			}
+			if len(repo.created) != tt.wantCreated {
+				t.Errorf("stored %v, want %d links", repo.created, tt.wantCreated)
+			}
+			if repo.createAllCalls != tt.wantCreateAll {
+				t.Errorf("CreateAll called %d times, want %d", repo.createAllCalls, tt.wantCreateAll)
+			}
+		})
+	}
+}
+
+func TestCreateBatchWithoutWorkspace(t *testing.T) {
+	repo := &fakeShortenRepository{}
+	s := &shortenService{repo: repo, workspaces: fakeWorkspaceRepository{}, configService: fakeConfigService{}}
+
+	_, err := s.CreateBatch(context.Background(), []models.ShortenRequest{{OriginalURL: "https://example.com"}}, false)
+	if !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("CreateBatch() error = %v, want ErrNoWorkspace", err)
+	}
+	if len(repo.created) != 0 {
+		t.Errorf("stored %v, want nothing", repo.created)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"portus/models"
+	"slices"
+	"testing"
+)
+
+func TestPickDestination(t *testing.T) {
+	tests := []struct {
+		name         string
+		destinations []models.Destination
+		// allowed lists the IDs that may be picked
+		allowed []uint64
+	}{
+		{
+			name:         "single destination",
+			destinations: []models.Destination{{ID: 1, Weight: 1}},
+			allowed:      []uint64{1},
+		},
+		{
+			name:         "zero weight is never picked",
+			destinations: []models.Destination{{ID: 1, Weight: 0}, {ID: 2, Weight: 3}, {ID: 3, Weight: 0}},
+			allowed:      []uint64{2},
+		},
+		{
+			name:         "all weights zero falls back to the first",
+			destinations: []models.Destination{{ID: 1, Weight: 0}, {ID: 2, Weight: 0}},
+			allowed:      []uint64{1},
+		},
+		{
+			name:         "weighted destinations",
+			destinations: []models.Destination{{ID: 1, Weight: 1}, {ID: 2, Weight: 99}},
+			allowed:      []uint64{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked := make(map[uint64]int)
+			for range 1000 {
+				picked[pickDestination(tt.destinations).ID]++
+			}
+
+			for id := range picked {
+				if !slices.Contains(tt.allowed, id) {
+					t.Errorf("picked destination %d, want one of %v", id, tt.allowed)
+				}
+			}
+		})
+	}
+}
+
+func TestPickDestinationFollowsWeights(t *testing.T) {
+	destinations := []models.Destination{{ID: 1, Weight: 1}, {ID: 2, Weight: 3}}
+
+	picked := make(map[uint64]int)
+	const draws = 20000
+	for range draws {
+		picked[pickDestination(destinations).ID]++
+	}
+
+	// 3 in 4 draws should go to the heavier destination; the bounds are
+	// loose enough that the test does not flake
+	if share := float64(picked[2]) / draws; share < 0.70 || share > 0.80 {
+		t.Errorf("heavier destination got %.2f of draws, want about 0.75", share)
+	}
+}
+
+func TestDestination(t *testing.T) {
+	split := []models.Destination{
+		{ID: 10, URL: "https://example.com/a", Weight: 1},
+		{ID: 11, URL: "https://example.com/b", Weight: 0},
+	}
+
+	tests := []struct {
+		name        string
+		shorten     models.Shorten
+		visitor     models.Visitor
+		wantURL     string
+		wantVariant uint64
+	}{
+		{
+			name:        "no destinations uses the original URL",
+			shorten:     models.Shorten{OriginalURL: "https://example.com"},
+			wantURL:     "https://example.com",
+			wantVariant: 0,
+		},
+		{
+			name:        "returning visitor keeps the assigned destination",
+			shorten:     models.Shorten{OriginalURL: "https://example.com", Destinations: split},
+			visitor:     models.Visitor{DestinationID: 11},
+			wantURL:     "https://example.com/b",
+			wantVariant: 11,
+		},
+		{
+			name:        "cookie of a deleted destination gets a new one",
+			shorten:     models.Shorten{OriginalURL: "https://example.com", Destinations: split},
+			visitor:     models.Visitor{DestinationID: 99},
+			wantURL:     "https://example.com/a",
+			wantVariant: 10,
+		},
+		{
+			name:        "new visitor is never sent to a zero weight destination",
+			shorten:     models.Shorten{OriginalURL: "https://example.com", Destinations: split},
+			wantURL:     "https://example.com/a",
+			wantVariant: 10,
+		},
+	}
+
+	s := &shortenService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, variant := s.destination(&tt.shorten, tt.visitor)
+			if url != tt.wantURL || variant != tt.wantVariant {
+				t.Errorf("destination() = %q, %d, want %q, %d", url, variant, tt.wantURL, tt.wantVariant)
+			}
+		})
+	}
+}
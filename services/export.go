@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportService streams links and their click events out of Portus
+type ExportService interface {
+	Export(ctx context.Context, query models.ExportQuery, w io.Writer) error
+}
+
+// ErrClickExportForbidden is returned when a caller without analytics:read
+// exports click events
+var ErrClickExportForbidden = errors.New("exporting click events requires the analytics:read scope")
+
+type exportService struct {
+	shortens      repository.ShortenRepository
+	analytics     repository.AnalyticsRepository
+	configService ConfigService
+}
+
+// NewExportService creates a new export service
+func NewExportService(shortens repository.ShortenRepository, analytics repository.AnalyticsRepository, configService ConfigService) ExportService {
+	return &exportService{
+		shortens:      shortens,
+		analytics:     analytics,
+		configService: configService,
+	}
+}
+
+// linkColumns is the header of CSV link exports. Nested fields are JSON.
+var linkColumns = []string{
+	"id", "shortCode", "shortUrl", "originalUrl", "domain",
+	"workspaceId", "ownerUserId", "ownerApiKeyId",
+	"createdAt", "updatedAt", "activatesAt", "expiresAt",
+	"clickCount", "maxClicks", "disabled", "passwordProtected", "redirectStatus",
+	"fallbackUrl", "passthrough", "campaign", "utm", "tags", "rules", "destinations",
+}
+
+// clickColumns is the header of CSV click exports
+var clickColumns = []string{
+	"id", "shortenId", "shortCode", "timestamp", "destinationId",
+	"referrer", "userAgent", "ipHash", "acceptLanguage",
+}
+
+// Export writes the links of the caller's workspace that query selects, or
+// their click events, to w. Invalid queries fail before anything is
+// written. Links and clicks are read from the database in batches and w is
+// flushed after each one, so exports of any size run in constant memory.
+func (s *exportService) Export(ctx context.Context, query models.ExportQuery, w io.Writer) error {
+	log := utils.LoggerFromContext(ctx)
+
+	filter, err := linkFilter(ctx, query.LinkFilterQuery)
+	if err != nil {
+		return err
+	}
+
+	out := newExportWriter(query.Format, w)
+
+	if query.Type == models.ExportTypeClicks {
+		principal := utils.PrincipalFromContext(ctx)
+		if principal == nil || !principal.HasScope(models.ScopeAnalyticsRead) {
+			return ErrClickExportForbidden
+		}
+
+		clickFilter := models.ClickFilter{Links: filter}
+		if query.From != "" {
+			from, err := utils.ParseTimeParam(query.From)
+			if err != nil {
+				return fmt.Errorf("%w: from: %v", ErrInvalidListQuery, err)
+			}
+			clickFilter.From = &from
+		}
+		if query.To != "" {
+			to, err := utils.ParseTimeParam(query.To)
+			if err != nil {
+				return fmt.Errorf("%w: to: %v", ErrInvalidListQuery, err)
+			}
+			clickFilter.To = &to
+		}
+
+		if err := out.begin(clickColumns); err != nil {
+			return err
+		}
+		err = s.analytics.EachClick(ctx, clickFilter, func(clicks []models.Click) error {
+			for i := range clicks {
+				click := &clicks[i]
+				if err := out.write(click, func() []string { return clickRow(click) }); err != nil {
+					return err
+				}
+			}
+			return out.flush()
+		})
+	} else {
+		appURL := s.configService.GetConfig().App.AppURL
+
+		if err := out.begin(linkColumns); err != nil {
+			return err
+		}
+		err = s.shortens.Each(ctx, filter, func(shortens []models.Shorten) error {
+			for i := range shortens {
+				data := &models.ShortenData{
+					Shorten:           &shortens[i],
+					ShortURL:          linkURL(appURL, &shortens[i]),
+					PasswordProtected: shortens[i].HasPassword(),
+				}
+				if err := out.write(data, func() []string { return linkRow(data) }); err != nil {
+					return err
+				}
+			}
+			return out.flush()
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Int("exported", out.count).Msg("Export stopped")
+		return err
+	}
+
+	log.Info().Str("type", string(query.Type)).Int("exported", out.count).Msg("Finished export")
+	return out.end()
+}
+
+// exportWriter encodes the items of an export in one format
+type exportWriter struct {
+	format models.ExportFormat
+	w      io.Writer
+	csv    *csv.Writer
+	json   *json.Encoder
+	count  int
+}
+
+// newExportWriter writes to w in format, CSV unless it is JSON or JSON Lines
+func newExportWriter(format models.ExportFormat, w io.Writer) *exportWriter {
+	out := &exportWriter{format: format, w: w}
+	if format == models.ExportFormatJSON || format == models.ExportFormatJSONL {
+		out.json = json.NewEncoder(w)
+	} else {
+		out.csv = csv.NewWriter(w)
+	}
+	return out
+}
+
+// begin writes the CSV header or opens the JSON array
+func (out *exportWriter) begin(header []string) error {
+	if out.csv != nil {
+		return out.csv.Write(header)
+	}
+	if out.format == models.ExportFormatJSON {
+		_, err := io.WriteString(out.w, "[\n")
+		return err
+	}
+	return nil
+}
+
+// write adds item, or the CSV row built by row
+func (out *exportWriter) write(item interface{}, row func() []string) error {
+	defer func() { out.count++ }()
+
+	if out.csv != nil {
+		cells := row()
+		for i, cell := range cells {
+			cells[i] = csvSafe(cell)
+		}
+		return out.csv.Write(cells)
+	}
+	if out.format == models.ExportFormatJSON && out.count > 0 {
+		if _, err := io.WriteString(out.w, ","); err != nil {
+			return err
+		}
+	}
+	return out.json.Encode(item)
+}
+
+// flush sends what has been written so far to the client
+func (out *exportWriter) flush() error {
+	if out.csv != nil {
+		out.csv.Flush()
+		if err := out.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := out.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// end closes the JSON array and flushes
+func (out *exportWriter) end() error {
+	if out.format == models.ExportFormatJSON {
+		if _, err := io.WriteString(out.w, "]\n"); err != nil {
+			return err
+		}
+	}
+	return out.flush()
+}
+
+func linkRow(data *models.ShortenData) []string {
+	shorten := data.Shorten
+
+	tags := make([]string, len(shorten.Tags))
+	for i, tag := range shorten.Tags {
+		tags[i] = tag.Name
+	}
+
+	var utm string
+	if shorten.UTM != (models.UTMParams{}) {
+		utm = csvJSON(shorten.UTM)
+	}
+
+	return []string{
+		strconv.FormatUint(shorten.ID, 10),
+		shorten.ShortCode,
+		data.ShortURL,
+		shorten.OriginalURL,
+		shorten.Domain,
+		strconv.FormatUint(shorten.WorkspaceID, 10),
+		csvID(shorten.OwnerUserID),
+		csvID(shorten.OwnerAPIKeyID),
+		csvTime(shorten.CreatedAt),
+		csvTime(shorten.UpdatedAt),
+		csvTime(shorten.ActivatesAt),
+		csvTime(shorten.ExpiresAt),
+		strconv.FormatUint(shorten.ClickCount, 10),
+		strconv.FormatUint(shorten.MaxClicks, 10),
+		strconv.FormatBool(shorten.Disabled),
+		strconv.FormatBool(data.PasswordProtected),
+		strconv.Itoa(shorten.RedirectStatus),
+		shorten.FallbackURL,
+		strconv.FormatBool(shorten.Passthrough),
+		shorten.Campaign,
+		utm,
+		csvList(tags),
+		csvList(shorten.Rules),
+		csvList(shorten.Destinations),
+	}
+}
+
+func clickRow(click *models.Click) []string {
+	return []string{
+		strconv.FormatUint(click.ID, 10),
+		strconv.FormatUint(click.ShortenID, 10),
+		click.ShortCode,
+		csvTime(click.Timestamp),
+		strconv.FormatUint(click.DestinationID, 10),
+		click.Referrer,
+		click.UserAgent,
+		click.IPHash,
+		click.AcceptLanguage,
+	}
+}
+
+// csvSafe keeps spreadsheets from evaluating cell as a formula. Destinations,
+// tags and referrers are user input, so a cell starting with a formula
+// character gets a leading quote.
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// csvTime formats t as RFC3339 in UTC, empty for the zero time
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvID formats an optional ID, empty when unset
+func csvID(id *uint64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(*id, 10)
+}
+
+// csvList encodes items as a JSON array, empty when there are none
+func csvList[T any](items []T) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return csvJSON(items)
+}
+
+// csvJSON encodes a nested value for a CSV cell
+func csvJSON(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"portus/models"
+	"strings"
+	"testing"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{cell: "", want: ""},
+		{cell: "https://example.com", want: "https://example.com"},
+		{cell: "=HYPERLINK(\"https://evil.example\")", want: "'=HYPERLINK(\"https://evil.example\")"},
+		{cell: "+1+1", want: "'+1+1"},
+		{cell: "-2+3", want: "'-2+3"},
+		{cell: "@SUM(A1)", want: "'@SUM(A1)"},
+		{cell: "\t=1", want: "'\t=1"},
+		{cell: "\r=1", want: "'\r=1"},
+		{cell: "a=1", want: "a=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			if got := csvSafe(tt.cell); got != tt.want {
+				t.Errorf("csvSafe(%q) = %q, want %q", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportWriterEscapesCSV(t *testing.T) {
+	var b strings.Builder
+	out := newExportWriter(models.ExportFormatCSV, &b)
+
+	err := out.write(nil, func() []string { return []string{"1", "=cmd|' /C calc'!A0", "spring"} })
+	if err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if err := out.end(); err != nil {
+		t.Fatalf("end() error = %v", err)
+	}
+
+	if want := "1,'=cmd|' /C calc'!A0,spring\n"; b.String() != want {
+		t.Errorf("wrote %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoLocator resolves the country of a client IP address
+type GeoLocator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of ip's country, or ""
+	// when it is unknown
+	Country(ip string) string
+}
+
+type geoIPLocator struct {
+	db *maxminddb.Reader
+}
+
+// countryRecord is the part of a MaxMind country or city record we read
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// NewGeoLocator opens the MaxMind-format database at path, e.g. GeoLite2
+// Country or City. Lookups never leave the machine. Without a path every
+// country is unknown.
+func NewGeoLocator(path string) (GeoLocator, error) {
+	if path == "" {
+		return noGeoLocator{}, nil
+	}
+
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &geoIPLocator{db: db}, nil
+}
+
+func (l *geoIPLocator) Country(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+
+	var record countryRecord
+	if err := l.db.Lookup(addr, &record); err != nil {
+		return ""
+	}
+	return strings.ToUpper(record.Country.ISOCode)
+}
+
+// noGeoLocator is used when no GeoIP database is configured
+type noGeoLocator struct{}
+
+func (noGeoLocator) Country(string) string {
+	return ""
+}
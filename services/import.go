@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"time"
+)
+
+// ImportService imports the links of other shorteners' exports
+type ImportService interface {
+	Start(ctx context.Context, req models.ImportRequest, file io.Reader) (*models.ImportJob, error)
+	Get(ctx context.Context, id uint64) (*models.ImportJob, error)
+	FailInterrupted(ctx context.Context) error
+}
+
+// Errors returned by ImportService
+var (
+	ErrInvalidImport  = errors.New("invalid export file")
+	ErrImportNotFound = errors.New("import job not found")
+)
+
+// importChunkSize is how many links an import creates between progress
+// updates
+const importChunkSize = 100
+
+type importService struct {
+	repo     repository.ImportRepository
+	shortens ShortenService
+}
+
+// NewImportService creates a new import service
+func NewImportService(repo repository.ImportRepository, shortens ShortenService) ImportService {
+	return &importService{
+		repo:     repo,
+		shortens: shortens,
+	}
+}
+
+// Start reads the export in file and imports its links into the caller's
+// workspace in the background. A file that cannot be read is rejected with
+// ErrInvalidImport before the job starts; links that cannot be imported are
+// reported by the job.
+func (s *importService) Start(ctx context.Context, req models.ImportRequest, file io.Reader) (*models.ImportJob, error) {
+	log := utils.LoggerFromContext(ctx)
+
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil || principal.WorkspaceID == 0 {
+		return nil, ErrNoWorkspace
+	}
+
+	rows, err := parseExport(req.Format, file)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file holds no links", ErrInvalidImport)
+	}
+
+	job, err := s.repo.Create(ctx, &models.ImportJob{
+		Source:        req.Source,
+		Format:        req.Format,
+		Status:        models.ImportStatusRunning,
+		Total:         len(rows),
+		Issues:        []models.ImportIssue{},
+		WorkspaceID:   principal.WorkspaceID,
+		OwnerUserID:   principal.UserID,
+		OwnerAPIKeyID: principal.APIKeyID,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating import job")
+		return nil, err
+	}
+
+	log.Info().Uint64("importId", job.ID).Int("links", len(rows)).Str("source", string(req.Source)).Msg("Started import")
+
+	// The job outlives the request, and the caller sees a copy so it never
+	// reads the job while it runs
+	started := *job
+	go s.run(context.WithoutCancel(ctx), job, exportColumns[req.Source], rows)
+
+	return &started, nil
+}
+
+// run imports rows in chunks, saving the job's progress after each one
+func (s *importService) run(ctx context.Context, job *models.ImportJob, fields exportFields, rows []exportRow) {
+	log := utils.LoggerFromContext(ctx).With().Uint64("importId", job.ID).Logger()
+
+	for start := 0; start < len(rows); start += importChunkSize {
+		chunk := rows[start:min(start+importChunkSize, len(rows))]
+
+		records := make([]models.ImportRecord, len(chunk))
+		errs := make([]error, len(chunk))
+		var valid []models.ImportRecord
+		for i, row := range chunk {
+			records[i], errs[i] = fields.toRecord(start+i+1, row)
+			if errs[i] == nil {
+				valid = append(valid, records[i])
+			}
+		}
+
+		results, err := s.shortens.Import(ctx, valid)
+		if err != nil {
+			log.Error().Err(err).Msg("Import stopped")
+			job.Error = "Import stopped after an internal error"
+			if errors.Is(err, ErrNoWorkspace) {
+				job.Error = "The workspace no longer exists"
+			}
+			s.finish(ctx, job, models.ImportStatusFailed)
+			return
+		}
+
+		next := 0
+		for i, record := range records {
+			err := errs[i]
+			if err == nil {
+				err = results[next].Err
+				next++
+			}
+			job.Processed++
+
+			switch {
+			case err == nil:
+				job.Imported++
+			case errors.Is(err, ErrShortCodeExists):
+				job.Conflicts++
+				addImportIssue(job, record, models.ErrorTypeConflict, "The short code already exists")
+			case errors.Is(err, ErrShortCodeReserved):
+				job.Conflicts++
+				addImportIssue(job, record, models.ErrorTypeConflict, "The short code is reserved")
+			case errors.Is(err, errInvalidRecord), errors.Is(err, ErrInvalidShortCode):
+				job.Failed++
+				addImportIssue(job, record, models.ErrorTypeValidation, err.Error())
+			default:
+				log.Error().Err(err).Int("row", record.Row).Str("code", record.Code).Msg("Failed to import link")
+				job.Failed++
+				addImportIssue(job, record, models.ErrorTypeInternalError, "Failed to create the link")
+			}
+		}
+
+		if err := s.repo.Save(ctx, job); err != nil {
+			log.Error().Err(err).Msg("Failed to save import progress")
+		}
+	}
+
+	log.Info().Int("imported", job.Imported).Int("conflicts", job.Conflicts).Int("failed", job.Failed).Msg("Finished import")
+	s.finish(ctx, job, models.ImportStatusCompleted)
+}
+
+// finish saves job as done with status
+func (s *importService) finish(ctx context.Context, job *models.ImportJob, status models.ImportStatus) {
+	log := utils.LoggerFromContext(ctx)
+
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+	if err := s.repo.Save(ctx, job); err != nil {
+		log.Error().Err(err).Uint64("importId", job.ID).Msg("Failed to save finished import")
+	}
+}
+
+// addImportIssue records why record was not imported, up to MaxImportIssues
+func addImportIssue(job *models.ImportJob, record models.ImportRecord, errorType models.ErrorType, message string) {
+	if len(job.Issues) >= models.MaxImportIssues {
+		return
+	}
+	job.Issues = append(job.Issues, models.ImportIssue{
+		Row:     record.Row,
+		Code:    record.Code,
+		Type:    errorType,
+		Message: message,
+	})
+}
+
+// Get returns the import job with id, if the caller started it or holds
+// links:admin
+func (s *importService) Get(ctx context.Context, id uint64) (*models.ImportJob, error) {
+	job, err := s.repo.FindById(ctx, id, linkScope(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrImportNotFound
+	}
+	return job, nil
+}
+
+// FailInterrupted fails the jobs a previous run of the process left running.
+// Jobs only run inside the process that started them, so they cannot be
+// resumed; importing the file again skips the links that made it in as
+// conflicts.
+func (s *importService) FailInterrupted(ctx context.Context) error {
+	log := utils.LoggerFromContext(ctx)
+
+	failed, err := s.repo.FailRunning(ctx, "The import was interrupted by a restart", time.Now())
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		log.Warn().Int64("jobs", failed).Msg("Marked imports interrupted by a restart as failed")
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"portus/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFields lists the names a field goes by in the exports of one
+// shortener, in order of preference
+type exportFields struct {
+	code    []string
+	url     []string
+	created []string
+	clicks  []string
+}
+
+// exportColumns maps the CSV columns and JSON keys of each source onto link
+// fields. Names are compared after normalizeKey.
+var exportColumns = map[models.ImportSource]exportFields{
+	// Bitly link exports and the bitlinks of API v4. Custom back-halves win
+	// over the generated bitlink.
+	models.ImportSourceBitly: {
+		code:    []string{"custom_link", "custom_bitlinks", "link", "bitlink", "short_link", "short_url", "id"},
+		url:     []string{"long_url", "long_link", "destination", "destination_url", "url"},
+		created: []string{"created_at", "created", "created_date", "date_created"},
+		clicks:  []string{"clicks", "total_clicks", "engagements", "click_count"},
+	},
+	// YOURLS tables (keyword, url, title, timestamp, ip, clicks) and the
+	// links of its stats API
+	models.ImportSourceYOURLS: {
+		code:    []string{"keyword", "shorturl", "short_url"},
+		url:     []string{"url", "long_url"},
+		created: []string{"timestamp", "created_at", "date"},
+		clicks:  []string{"clicks"},
+	},
+}
+
+// exportTimeLayouts are the date formats found in exports. Times without a
+// zone are taken as UTC.
+var exportTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700", // Bitly API
+	"2006-01-02 15:04:05",      // YOURLS
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// errInvalidRecord marks a link of an export that cannot be imported
+var errInvalidRecord = errors.New("invalid link")
+
+// exportRow is one link of an export, keyed by normalized column name
+type exportRow map[string]string
+
+// normalizeKey makes "Long URL", "long-url" and "long_url" the same column
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(key, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+// parseExport reads the links of an export file in format
+func parseExport(format models.ImportFormat, r io.Reader) ([]exportRow, error) {
+	switch format {
+	case models.ImportFormatCSV:
+		return parseExportCSV(r)
+	case models.ImportFormatJSON:
+		return parseExportJSON(r)
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+}
+
+func parseExportCSV(r io.Reader) ([]exportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	for i := range header {
+		header[i] = normalizeKey(header[i])
+	}
+
+	var rows []exportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+
+		row := make(exportRow, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseExportJSON accepts an array of links, or an object holding them under
+// "links" either as an array (Bitly) or keyed link_1, link_2, ... (YOURLS)
+func parseExportJSON(r io.Reader) ([]exportRow, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	links := document
+	if object, ok := document.(map[string]interface{}); ok {
+		links = object["links"]
+	}
+
+	var items []interface{}
+	switch links := links.(type) {
+	case []interface{}:
+		items = links
+	case map[string]interface{}:
+		keys := make([]string, 0, len(links))
+		for key := range links {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keySuffix(keys[i]) < keySuffix(keys[j])
+		})
+		for _, key := range keys {
+			items = append(items, links[key])
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected an array of links or an object with \"links\"", ErrInvalidImport)
+	}
+
+	rows := make([]exportRow, len(items))
+	for i, item := range items {
+		object, _ := item.(map[string]interface{})
+		rows[i] = make(exportRow, len(object))
+		for key, value := range object {
+			rows[i][normalizeKey(key)] = jsonString(value)
+		}
+	}
+	return rows, nil
+}
+
+// keySuffix is the number that ends a YOURLS key such as link_12
+func keySuffix(key string) int {
+	n, _ := strconv.Atoi(key[strings.LastIndexAny(key, "_-")+1:])
+	return n
+}
+
+// jsonString flattens a JSON value into the string a CSV cell would hold.
+// Arrays give their first element.
+func jsonString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	case []interface{}:
+		if len(value) > 0 {
+			return jsonString(value[0])
+		}
+	}
+	return ""
+}
+
+// lookup returns the first non-empty value of names in row
+func (row exportRow) lookup(names []string) string {
+	for _, name := range names {
+		if value := row[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// toRecord maps row onto link fields. The record holds whatever could be
+// read even when err is set, so the issue can name the code.
+func (fields exportFields) toRecord(index int, row exportRow) (models.ImportRecord, error) {
+	record := models.ImportRecord{
+		Row:  index,
+		Code: codeFromLink(row.lookup(fields.code)),
+		URL:  row.lookup(fields.url),
+	}
+
+	if record.URL == "" {
+		return record, fmt.Errorf("%w: missing destination URL", errInvalidRecord)
+	}
+	// Other shorteners only export web links, so anything else, such as a
+	// javascript: or data: URL, is not imported
+	if u, err := url.Parse(record.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return record, fmt.Errorf("%w: destination %q is not an http or https URL", errInvalidRecord, record.URL)
+	}
+
+	if created := row.lookup(fields.created); created != "" {
+		createdAt, err := parseExportTime(created)
+		if err != nil {
+			return record, fmt.Errorf("%w: %v", errInvalidRecord, err)
+		}
+		record.CreatedAt = createdAt
+	}
+
+	if clicks := row.lookup(fields.clicks); clicks != "" {
+		count, err := strconv.ParseUint(strings.ReplaceAll(clicks, ",", ""), 10, 64)
+		if err != nil {
+			return record, fmt.Errorf("%w: click count %q is not a number", errInvalidRecord, clicks)
+		}
+		record.Clicks = count
+	}
+
+	return record, nil
+}
+
+// codeFromLink returns the code of a short link given as a code, a path such
+// as bit.ly/abc or a full URL. Lists of links give their first one.
+func codeFromLink(link string) string {
+	if fields := strings.FieldsFunc(link, func(r rune) bool {
+		return r == ',' || r == ' '
+	}); len(fields) > 0 {
+		link = fields[0]
+	}
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		link = link[:i]
+	}
+	link = strings.TrimRight(link, "/")
+	return link[strings.LastIndex(link, "/")+1:]
+}
+
+// parseExportTime reads a date in one of exportTimeLayouts or as Unix seconds
+func parseExportTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	for _, layout := range exportTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
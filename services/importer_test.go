@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"portus/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  models.ImportFormat
+		file    string
+		want    []exportRow
+		wantErr bool
+	}{
+		{
+			name:   "CSV with normalized headers",
+			format: models.ImportFormatCSV,
+			file:   "\uFEFFLong URL,Custom-Link, Clicks \nhttps://example.com, bit.ly/spring ,12\n",
+			want: []exportRow{
+				{"long_url": "https://example.com", "custom_link": "bit.ly/spring", "clicks": "12"},
+			},
+		},
+		{
+			name:   "CSV rows shorter or longer than the header",
+			format: models.ImportFormatCSV,
+			file:   "keyword,url\nabc\ndef,https://example.com,extra\n",
+			want: []exportRow{
+				{"keyword": "abc"},
+				{"keyword": "def", "url": "https://example.com"},
+			},
+		},
+		{
+			name:    "empty CSV",
+			format:  models.ImportFormatCSV,
+			file:    "",
+			wantErr: true,
+		},
+		{
+			name:    "malformed CSV",
+			format:  models.ImportFormatCSV,
+			file:    "keyword,url\n\"abc,https://example.com\n",
+			wantErr: true,
+		},
+		{
+			name:   "JSON array",
+			format: models.ImportFormatJSON,
+			file:   `[{"keyword": "abc", "url": "https://example.com", "clicks": 3}]`,
+			want: []exportRow{
+				{"keyword": "abc", "url": "https://example.com", "clicks": "3"},
+			},
+		},
+		{
+			name:   "Bitly links array with list values",
+			format: models.ImportFormatJSON,
+			file:   `{"links": [{"id": "bit.ly/abc", "long_url": "https://example.com", "custom_bitlinks": ["bit.ly/spring", "bit.ly/other"], "archived": false}]}`,
+			want: []exportRow{
+				{"id": "bit.ly/abc", "long_url": "https://example.com", "custom_bitlinks": "bit.ly/spring", "archived": "false"},
+			},
+		},
+		{
+			name:   "YOURLS links keyed by number",
+			format: models.ImportFormatJSON,
+			file:   `{"links": {"link_10": {"shorturl": "c"}, "link_2": {"shorturl": "b"}, "link_1": {"shorturl": "a"}}}`,
+			want: []exportRow{
+				{"shorturl": "a"},
+				{"shorturl": "b"},
+				{"shorturl": "c"},
+			},
+		},
+		{
+			name:    "JSON without links",
+			format:  models.ImportFormatJSON,
+			file:    `{"status": "ok"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			format:  models.ImportFormatJSON,
+			file:    `[{"keyword": `,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			file:    "<links/>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseExport(tt.format, strings.NewReader(tt.file))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImport) {
+					t.Fatalf("parseExport() error = %v, want ErrInvalidImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExport() error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("parseExport() = %v, want %v", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestToRecord(t *testing.T) {
+	bitly := exportColumns[models.ImportSourceBitly]
+	yourls := exportColumns[models.ImportSourceYOURLS]
+
+	tests := []struct {
+		name    string
+		fields  exportFields
+		row     exportRow
+		want    models.ImportRecord
+		wantErr bool
+	}{
+		{
+			name:   "custom back-half wins over the bitlink",
+			fields: bitly,
+			row:    exportRow{"link": "https://bit.ly/3xYz", "custom_link": "bit.ly/spring", "long_url": "https://example.com"},
+			want:   models.ImportRecord{Row: 1, Code: "spring", URL: "https://example.com"},
+		},
+		{
+			name:   "YOURLS row with date and clicks",
+			fields: yourls,
+			row:    exportRow{"keyword": "abc", "url": "https://example.com", "timestamp": "2024-03-01 10:30:00", "clicks": "1,204"},
+			want: models.ImportRecord{
+				Row:       1,
+				Code:      "abc",
+				URL:       "https://example.com",
+				CreatedAt: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+				Clicks:    1204,
+			},
+		},
+		{
+			name:   "Unix timestamp",
+			fields: yourls,
+			row:    exportRow{"keyword": "abc", "url": "https://example.com", "timestamp": "1700000000"},
+			want:   models.ImportRecord{Row: 1, Code: "abc", URL: "https://example.com", CreatedAt: time.Unix(1700000000, 0).UTC()},
+		},
+		{
+			name:    "missing destination",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc"},
+			want:    models.ImportRecord{Row: 1, Code: "abc"},
+			wantErr: true,
+		},
+		{
+			name:    "relative destination",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc", "url": "example.com/page"},
+			want:    models.ImportRecord{Row: 1, Code: "abc", URL: "example.com/page"},
+			wantErr: true,
+		},
+		{
+			name:    "javascript destination",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc", "url": "javascript:alert(1)"},
+			want:    models.ImportRecord{Row: 1, Code: "abc", URL: "javascript:alert(1)"},
+			wantErr: true,
+		},
+		{
+			name:    "data destination",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc", "url": "data:text/html,<script>alert(1)</script>"},
+			want:    models.ImportRecord{Row: 1, Code: "abc", URL: "data:text/html,<script>alert(1)</script>"},
+			wantErr: true,
+		},
+		{
+			name:   "uppercase scheme",
+			fields: yourls,
+			row:    exportRow{"keyword": "abc", "url": "HTTPS://example.com"},
+			want:   models.ImportRecord{Row: 1, Code: "abc", URL: "HTTPS://example.com"},
+		},
+		{
+			name:    "unrecognized date",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc", "url": "https://example.com", "timestamp": "March 1st"},
+			want:    models.ImportRecord{Row: 1, Code: "abc", URL: "https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "click count that is not a number",
+			fields:  yourls,
+			row:     exportRow{"keyword": "abc", "url": "https://example.com", "clicks": "many"},
+			want:    models.ImportRecord{Row: 1, Code: "abc", URL: "https://example.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := tt.fields.toRecord(1, tt.row)
+			if tt.wantErr != errors.Is(err, errInvalidRecord) {
+				t.Fatalf("toRecord() error = %v, want invalid record: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Errorf("toRecord() = %+v, want %+v", record, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeFromLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "abc", want: "abc"},
+		{link: "bit.ly/abc", want: "abc"},
+		{link: "https://bit.ly/abc/", want: "abc"},
+		{link: "https://sho.rt/abc?utm_source=x#top", want: "abc"},
+		{link: "bit.ly/spring, bit.ly/other", want: "spring"},
+		{link: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			if got := codeFromLink(tt.link); got != tt.want {
+				t.Errorf("codeFromLink(%q) = %q, want %q", tt.link, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExportTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-03-01T10:30:00Z", want: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "2024-03-01T10:30:00+0000", want: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "2024-03-01 10:30:00", want: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "1700000000", want: time.Unix(1700000000, 0).UTC()},
+		{value: "01/03/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseExportTime(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExportTime(%q) error = %v, want error: %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseExportTime(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
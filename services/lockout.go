@@ -0,0 +1,87 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptLimiter locks a key out after repeated failures. Failures older
+// than the lockout window are forgotten. State lives in memory, so it is per
+// instance and resets on restart.
+type attemptLimiter struct {
+	mu        sync.Mutex
+	attempts  map[string]*failedAttempts
+	lastSweep time.Time
+}
+
+type failedAttempts struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func newAttemptLimiter() *attemptLimiter {
+	return &attemptLimiter{
+		attempts: make(map[string]*failedAttempts),
+	}
+}
+
+// LockedFor returns how long key remains locked out, or zero
+func (l *attemptLimiter) LockedFor(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.attempts[key]
+	if !ok || !now.Before(state.lockedUntil) {
+		return 0
+	}
+	return state.lockedUntil.Sub(now)
+}
+
+// Fail records a failure for key and returns how long it is now locked
+// out, or zero while it has attempts left
+func (l *attemptLimiter) Fail(key string, maxAttempts int, lockout time.Duration, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(lockout, now)
+
+	state, ok := l.attempts[key]
+	if !ok || now.Sub(state.lastFailure) > lockout {
+		state = &failedAttempts{}
+		l.attempts[key] = state
+	}
+
+	state.count++
+	state.lastFailure = now
+	if state.count < maxAttempts {
+		return 0
+	}
+
+	state.count = 0
+	state.lockedUntil = now.Add(lockout)
+	return lockout
+}
+
+// Reset forgets the failures of key
+func (l *attemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+}
+
+// sweep drops expired entries at most once per lockout window so the map
+// cannot grow without bound. The caller must hold l.mu.
+func (l *attemptLimiter) sweep(lockout time.Duration, now time.Time) {
+	if now.Sub(l.lastSweep) < lockout {
+		return
+	}
+	l.lastSweep = now
+
+	for key, state := range l.attempts {
+		if now.Sub(state.lastFailure) > lockout && !now.Before(state.lockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiter(t *testing.T) {
+	const maxAttempts = 3
+	const lockout = 10 * time.Minute
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Each step fails (or resets) key at start plus offset and checks the
+	// lockout Fail returns and the one LockedFor reports right after
+	type step struct {
+		offset     time.Duration
+		reset      bool
+		wantFail   time.Duration
+		wantLocked time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "failures below the limit do not lock",
+			steps: []step{
+				{offset: 0},
+				{offset: time.Minute},
+			},
+		},
+		{
+			name: "reaching the limit locks for the lockout",
+			steps: []step{
+				{offset: 0},
+				{offset: time.Second},
+				{offset: 2 * time.Second, wantFail: lockout, wantLocked: lockout},
+			},
+		},
+		{
+			name: "failures older than the window are forgotten",
+			steps: []step{
+				{offset: 0},
+				{offset: time.Second},
+				{offset: lockout + 2*time.Second},
+				{offset: lockout + 3*time.Second},
+			},
+		},
+		{
+			name: "a reset forgets earlier failures",
+			steps: []step{
+				{offset: 0},
+				{offset: time.Second},
+				{offset: 2 * time.Second, reset: true},
+				{offset: 3 * time.Second},
+				{offset: 4 * time.Second},
+			},
+		},
+		{
+			name: "counting starts over once a lockout expires",
+			steps: []step{
+				{offset: 0},
+				{offset: 0},
+				{offset: 0, wantFail: lockout, wantLocked: lockout},
+				{offset: lockout},
+				{offset: lockout},
+				{offset: lockout, wantFail: lockout, wantLocked: lockout},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newAttemptLimiter()
+			for i, step := range tt.steps {
+				now := start.Add(step.offset)
+				if step.reset {
+					limiter.Reset("key")
+					continue
+				}
+				if got := limiter.Fail("key", maxAttempts, lockout, now); got != step.wantFail {
+					t.Errorf("step %d: Fail() = %s, want %s", i, got, step.wantFail)
+				}
+				if got := limiter.LockedFor("key", now); got != step.wantLocked {
+					t.Errorf("step %d: LockedFor() = %s, want %s", i, got, step.wantLocked)
+				}
+			}
+		})
+	}
+}
+
+func TestAttemptLimiterLockoutExpires(t *testing.T) {
+	const lockout = 10 * time.Minute
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	limiter := newAttemptLimiter()
+	limiter.Fail("key", 1, lockout, start)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   time.Duration
+	}{
+		{name: "right after the lockout starts", offset: 0, want: lockout},
+		{name: "halfway through", offset: lockout / 2, want: lockout / 2},
+		{name: "when it ends", offset: lockout, want: 0},
+		{name: "long after", offset: 2 * lockout, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiter.LockedFor("key", start.Add(tt.offset)); got != tt.want {
+				t.Errorf("LockedFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := limiter.LockedFor("other", start); got != 0 {
+		t.Errorf("LockedFor() of another key = %s, want 0", got)
+	}
+}
+
+func TestAttemptLimiterSweep(t *testing.T) {
+	const lockout = 10 * time.Minute
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	limiter := newAttemptLimiter()
+	limiter.Fail("stale", 5, lockout, start)
+	limiter.Fail("recent", 5, lockout, start.Add(lockout/2))
+
+	// Sweeps run at most once per window. The first failure after a full
+	// window drops entries whose failures are all older than the window.
+	limiter.Fail("fresh", 5, lockout, start.Add(lockout+time.Second))
+
+	for key, want := range map[string]bool{"stale": false, "recent": true, "fresh": true} {
+		if _, ok := limiter.attempts[key]; ok != want {
+			t.Errorf("entry %q present = %v, want %v", key, ok, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import "testing"
+
+func TestPassThrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		path   string
+		query  string
+		want   string
+	}{
+		{
+			name:   "nothing to pass through",
+			target: "https://example.com/help?a=1",
+			want:   "https://example.com/help?a=1",
+		},
+		{
+			name:   "path is appended",
+			target: "https://example.com/help",
+			path:   "docs/page",
+			want:   "https://example.com/help/docs/page",
+		},
+		{
+			name:   "no double slash after a trailing slash",
+			target: "https://example.com/help/",
+			path:   "docs",
+			want:   "https://example.com/help/docs",
+		},
+		{
+			name:   "trailing slash of the path is kept",
+			target: "https://example.com/help",
+			path:   "docs/",
+			want:   "https://example.com/help/docs/",
+		},
+		{
+			name:   "decoded percent sign is escaped again",
+			target: "https://example.com/help",
+			path:   "100%",
+			want:   "https://example.com/help/100%25",
+		},
+		{
+			name:   "decoded escape sequence stays literal",
+			target: "https://example.com/help",
+			path:   "a%25b",
+			want:   "https://example.com/help/a%2525b",
+		},
+		{
+			name:   "spaces are escaped",
+			target: "https://example.com/help",
+			path:   "a b",
+			want:   "https://example.com/help/a%20b",
+		},
+		{
+			name:   "dot segments cannot leave the destination path",
+			target: "https://example.com/help",
+			path:   "../../etc/passwd",
+			want:   "https://example.com/help/etc/passwd",
+		},
+		{
+			name:   "dot segments within the path are resolved",
+			target: "https://example.com/help",
+			path:   "a/./b/../c",
+			want:   "https://example.com/help/a/c",
+		},
+		{
+			name:   "path of only dot segments adds nothing",
+			target: "https://example.com/help",
+			path:   "..",
+			want:   "https://example.com/help",
+		},
+		{
+			name:   "escaped slash in the destination is kept",
+			target: "https://example.com/a%2Fb",
+			path:   "c",
+			want:   "https://example.com/a%2Fb/c",
+		},
+		{
+			name:   "query is merged",
+			target: "https://example.com/help",
+			query:  "ref=x&b=2",
+			want:   "https://example.com/help?ref=x&b=2",
+		},
+		{
+			name:   "destination parameters win, repeated keys are kept",
+			target: "https://example.com/help?x=1",
+			query:  "x=2&y=3&y=4",
+			want:   "https://example.com/help?x=1&y=3&y=4",
+		},
+		{
+			name:   "configured utm parameter beats the visitor's",
+			target: "https://example.com/help?utm_source=newsletter",
+			query:  "utm_source=spam&ref=x",
+			want:   "https://example.com/help?utm_source=newsletter&ref=x",
+		},
+		{
+			name:   "empty and malformed parameters are dropped",
+			target: "https://example.com/help",
+			query:  "a=1&&%zz=2&b",
+			want:   "https://example.com/help?a=1&b",
+		},
+		{
+			name:   "fragment stays at the end",
+			target: "https://example.com/help#top",
+			path:   "docs",
+			query:  "a=1",
+			want:   "https://example.com/help/docs?a=1#top",
+		},
+		{
+			name:   "unparsable destination is left alone",
+			target: "https://exa mple.com/%zz",
+			path:   "docs",
+			want:   "https://exa mple.com/%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passThrough(tt.target, tt.path, tt.query); got != tt.want {
+				t.Errorf("passThrough(%q, %q, %q) = %q, want %q", tt.target, tt.path, tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"sync"
+	"time"
+)
+
+// ClickAggregator rolls raw click events into hourly and daily buckets so
+// timeseries queries never have to scan the clicks table
+type ClickAggregator interface {
+	Aggregate(ctx context.Context) error
+	Start(ctx context.Context, every time.Duration)
+}
+
+type clickAggregator struct {
+	repo    repository.AnalyticsRepository
+	lastRun time.Time
+	runLock sync.Mutex
+}
+
+// NewClickAggregator creates a new click aggregator
+func NewClickAggregator(repo repository.AnalyticsRepository) ClickAggregator {
+	return &clickAggregator{
+		repo: repo,
+	}
+}
+
+// Aggregate recomputes every bucket touched since the previous run. The
+// bucket containing the previous run is always recomputed, which picks up
+// clicks that were still being written when it ran.
+func (a *clickAggregator) Aggregate(ctx context.Context) error {
+	log := utils.LoggerFromContext(ctx)
+
+	a.runLock.Lock()
+	defer a.runLock.Unlock()
+
+	startedAt := time.Now().UTC()
+
+	since := a.lastRun
+	if since.IsZero() {
+		// First run since startup, resume from the newest hourly bucket
+		latest, err := a.repo.LatestRollup(ctx, models.RollupIntervalHour)
+		if err != nil {
+			return err
+		}
+		if latest != nil {
+			since = *latest
+		}
+	}
+
+	since = since.UTC().Truncate(models.RollupIntervalHour.Duration())
+	log.Debug().Time("since", since).Msg("Rolling up clicks")
+
+	if err := a.repo.RollupHourly(ctx, since); err != nil {
+		return err
+	}
+
+	if err := a.repo.RollupDaily(ctx, since.Truncate(models.RollupIntervalDay.Duration())); err != nil {
+		return err
+	}
+
+	a.lastRun = startedAt
+	return nil
+}
+
+// Start runs Aggregate immediately and then on every tick until ctx is done
+func (a *clickAggregator) Start(ctx context.Context, every time.Duration) {
+	log := utils.LoggerFromContext(ctx)
+
+	if every <= 0 {
+		log.Info().Msg("Click rollups disabled")
+		return
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		if err := a.Aggregate(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to roll up clicks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,93 +4,878 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
 	"portus/models"
 	"portus/repository"
 	"portus/utils"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // ShortenService provides methods to interact with URL shortening
 type ShortenService interface {
-	GetOriginalURL(ctx context.Context, code string) (string, error)
+	GetOriginalURL(ctx context.Context, code string, visitor models.Visitor) (*models.RedirectResult, error)
 	Create(ctx context.Context, req models.ShortenRequest) (*models.ShortenData, error)
+	CreateBatch(ctx context.Context, reqs []models.ShortenRequest, atomic bool) ([]BatchResult, error)
+	Import(ctx context.Context, records []models.ImportRecord) ([]BatchResult, error)
 	Update(ctx context.Context, code string, req models.ShortenRequest) (*models.ShortenData, error)
 	Delete(ctx context.Context, code string) error
+	Bulk(ctx context.Context, req models.BulkLinkRequest) (*models.BulkLinkResult, error)
 	GetById(ctx context.Context, id uint64) *models.ShortenData
 	GetByOriginalUrl(ctx context.Context, url string) (*models.ShortenData, bool, error)
 	ShortCodeExists(ctx context.Context, randomCode string) (bool, error)
+	List(ctx context.Context, query models.ShortenListQuery) (*models.PaginatedData[models.ShortenData], error)
 }
 
+// Errors returned by ShortenService
+var (
+	ErrShortURLNotFound  = errors.New("short URL not found")
+	ErrShortURLExpired   = errors.New("shortened URL has expired")
+	ErrShortURLExhausted = errors.New("shortened URL has reached its click limit")
+	ErrShortURLNotActive = errors.New("shortened URL is not active yet")
+	ErrShortURLDisabled  = errors.New("shortened URL is disabled")
+	ErrInvalidSchedule   = errors.New("invalid link schedule")
+	ErrUnknownCampaign   = errors.New("unknown campaign template")
+	ErrShortCodeExists   = errors.New("short code already exists")
+	ErrShortCodeReserved = errors.New("short code is reserved")
+	ErrInvalidShortCode  = errors.New("invalid short code")
+)
+
+// shortCodePattern keeps custom codes to a single path segment that is safe
+// in URLs
+var shortCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Errors returned when resolving a password-protected link
+var (
+	ErrPasswordRequired = errors.New("this link requires a password")
+	ErrInvalidPassword  = errors.New("invalid link password")
+	ErrPasswordLocked   = errors.New("too many wrong passwords for this link")
+)
+
+// PasswordLockedError is returned while a visitor is locked out of a
+// password-protected link. It matches ErrPasswordLocked.
+type PasswordLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PasswordLockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrPasswordLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *PasswordLockedError) Unwrap() error {
+	return ErrPasswordLocked
+}
+
+// NotActiveError is returned for links whose activation time has not come
+// yet. It matches ErrShortURLNotActive.
+type NotActiveError struct {
+	ActivatesAt time.Time
+}
+
+func (e *NotActiveError) Error() string {
+	return fmt.Sprintf("%s, it activates at %s", ErrShortURLNotActive, e.ActivatesAt.UTC().Format(time.RFC3339))
+}
+
+func (e *NotActiveError) Unwrap() error {
+	return ErrShortURLNotActive
+}
+
+// UnavailableError is returned for links that have expired, are disabled or
+// have used up their clicks. It matches the reason, and carries where the
+// visitor should be sent instead.
+type UnavailableError struct {
+	Reason error
+
+	// FallbackURL is the link's, its workspace's or the global fallback URL,
+	// empty when none is set
+	FallbackURL string
+	// FallbackPage is redirect.fallbackPage, for browsers when there is no
+	// fallback URL
+	FallbackPage string
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Reason.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Reason
+}
+
+// ErrBatchAborted is reported for the valid items of an atomic batch that
+// was not created because other items failed
+var ErrBatchAborted = errors.New("not created because other links in the batch failed")
+
+// BatchResult is the outcome of creating one link of a batch: the link, or
+// why it was not created
+type BatchResult struct {
+	Data *models.ShortenData
+	Err  error
+}
+
+// ErrInvalidListQuery is returned when listing parameters cannot be parsed
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ErrInvalidBulkRequest is returned when a bulk operation selects no links
+// or cannot be carried out as asked
+var ErrInvalidBulkRequest = errors.New("invalid bulk request")
+
+// maxCodeAttempts bounds how many random codes Create tries before giving up
+const maxCodeAttempts = 10
+
+// defaultPageSize is used when a listing does not ask for a page size
+const defaultPageSize = 20
+
 type shortenService struct {
-	repo    repository.ShortenRepository
-	baseURL string
+	repo          repository.ShortenRepository
+	workspaces    repository.WorkspaceRepository
+	analytics     AnalyticsService
+	configService ConfigService
+	geo           GeoLocator
+
+	// passwordAttempts locks visitors out of protected links after repeated
+	// wrong passwords, keyed by link and client IP
+	passwordAttempts *attemptLimiter
 }
 
-// NewShortenService creates a new shortening service
-func NewShortenService(repo repository.ShortenRepository, baseURL string) ShortenService {
+// NewShortenService creates a new shortening service. The app URL and page
+// size limit are read from configService on every call, so config updates
+// apply without a restart.
+func NewShortenService(repo repository.ShortenRepository, workspaces repository.WorkspaceRepository, analytics AnalyticsService, configService ConfigService, geo GeoLocator) ShortenService {
 	return &shortenService{
-		repo:    repo,
-		baseURL: baseURL,
+		repo:          repo,
+		workspaces:    workspaces,
+		analytics:     analytics,
+		configService: configService,
+		geo:           geo,
+
+		passwordAttempts: newAttemptLimiter(),
 	}
 }
 
+// shortURL builds the public URL a link resolves at
+func (s *shortenService) shortURL(shorten *models.Shorten) string {
+	return linkURL(s.configService.GetConfig().App.AppURL, shorten)
+}
+
+// linkURL builds the public URL of shorten for the instance at appURL.
+// Links on a workspace domain keep the scheme of appURL.
+func linkURL(appURL string, shorten *models.Shorten) string {
+	if shorten.Domain == "" {
+		return fmt.Sprintf("%s/%s", appURL, shorten.ShortCode)
+	}
+
+	scheme := "https"
+	if parsed, err := url.Parse(appURL); err == nil && parsed.Scheme != "" {
+		scheme = parsed.Scheme
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, shorten.Domain, shorten.ShortCode)
+}
+
+// toData wraps a link for API responses
+func (s *shortenService) toData(shorten *models.Shorten) *models.ShortenData {
+	return &models.ShortenData{
+		Shorten:           shorten,
+		ShortURL:          s.shortURL(shorten),
+		PasswordProtected: shorten.HasPassword(),
+	}
+}
+
+// readScope returns the links the caller in ctx may read: every link of its
+// workspace, whoever created it. A context without a principal sees nothing.
+func readScope(ctx context.Context) models.LinkScope {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil {
+		return models.LinkScope{Owner: &models.LinkOwner{}}
+	}
+	return models.LinkScope{WorkspaceID: principal.WorkspaceID}
+}
+
+// linkScope returns the links the caller in ctx may change: those of its
+// workspace, further limited to its own unless it holds links:admin. A
+// context without a principal sees nothing.
+func linkScope(ctx context.Context) models.LinkScope {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil {
+		return models.LinkScope{Owner: &models.LinkOwner{}}
+	}
+
+	scope := models.LinkScope{WorkspaceID: principal.WorkspaceID}
+	if !principal.HasScope(models.ScopeLinksAdmin) {
+		scope.Owner = &models.LinkOwner{
+			UserID:   principal.UserID,
+			APIKeyID: principal.APIKeyID,
+		}
+	}
+	return scope
+}
+
+// domainForHost maps a request host onto the domain links are stored under:
+// the host itself if a workspace serves on it, the default domain otherwise
+func (s *shortenService) domainForHost(ctx context.Context, host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+
+	workspace, err := s.workspaces.FindByDomain(ctx, utils.NormalizeHost(host))
+	if err != nil {
+		return "", err
+	}
+
+	if workspace == nil || workspace.Domain == nil {
+		return "", nil
+	}
+	return *workspace.Domain, nil
+}
+
 func (s *shortenService) GetById(ctx context.Context, id uint64) *models.ShortenData {
 	shorten, err := s.repo.FindById(ctx, id)
 	if err != nil {
 		return nil
 	}
 
-	return &models.ShortenData{
-		Shorten:  shorten,
-		ShortURL: fmt.Sprintf("%s/%s", s.baseURL, shorten.ShortCode),
+	return s.toData(shorten)
+}
+
+func (s *shortenService) GetOriginalURL(ctx context.Context, code string, visitor models.Visitor) (*models.RedirectResult, error) {
+	domain, err := s.domainForHost(ctx, visitor.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	shorten, err := s.repo.FindByCode(ctx, domain, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if shorten == nil {
+		return nil, ErrShortURLNotFound
+	}
+
+	// Only passthrough links resolve with a trailing path
+	if visitor.Path != "" && !shorten.Passthrough {
+		return nil, ErrShortURLNotFound
+	}
+
+	// Check if expired or not active yet
+	now := time.Now()
+	if !shorten.ExpiresAt.IsZero() && shorten.ExpiresAt.Before(now) {
+		return nil, s.unavailable(ctx, shorten, ErrShortURLExpired)
+	}
+	if shorten.ActivatesAt.After(now) {
+		return nil, &NotActiveError{ActivatesAt: shorten.ActivatesAt}
+	}
+	if shorten.Disabled {
+		return nil, s.unavailable(ctx, shorten, ErrShortURLDisabled)
+	}
+
+	if shorten.HasPassword() {
+		if err := s.checkPassword(shorten, visitor); err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := s.redirectStatus(ctx, shorten)
+	if err != nil {
+		return nil, err
+	}
+
+	// Click-limited links claim their click before redirecting so the limit
+	// holds under concurrent visits. Other links count it in the background.
+	if shorten.MaxClicks > 0 {
+		claimed, err := s.repo.ClaimClick(ctx, shorten.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, s.unavailable(ctx, shorten, ErrShortURLExhausted)
+		}
+	}
+
+	// UTM parameters go in before passthrough, so the visitor's query cannot
+	// override them
+	target, destinationID := s.destination(shorten, visitor)
+	target, err = s.addUTM(ctx, shorten, target)
+	if err != nil {
+		return nil, err
+	}
+	if shorten.Passthrough {
+		target = passThrough(target, visitor.Path, visitor.Query)
+	}
+
+	// Record the click asynchronously; it must outlive the request context
+	go func(ctx context.Context) {
+		log := utils.LoggerFromContext(ctx)
+		if shorten.MaxClicks == 0 {
+			if _, err := s.repo.ClaimClick(ctx, shorten.ID); err != nil {
+				log.Error().Err(err).Str("code", code).Msg("Failed to count click")
+			}
+		}
+		if err := s.analytics.RecordClick(ctx, shorten, visitor, destinationID); err != nil {
+			log.Error().Err(err).Str("code", code).Msg("Failed to record click")
+		}
+	}(context.WithoutCancel(ctx))
+
+	return &models.RedirectResult{
+		URL:           target,
+		StatusCode:    status,
+		DestinationID: destinationID,
+	}, nil
+}
+
+// destination returns the URL of the first rule of shorten that matches
+// visitor, else the split destination visitor is assigned to, else its
+// original URL. The destination ID is zero unless a split destination won.
+func (s *shortenService) destination(shorten *models.Shorten, visitor models.Visitor) (string, uint64) {
+	if target := s.matchRule(shorten, visitor); target != "" {
+		return target, 0
+	}
+
+	if len(shorten.Destinations) == 0 {
+		return shorten.OriginalURL, 0
 	}
+
+	// Returning visitors keep their destination while it exists
+	for _, destination := range shorten.Destinations {
+		if destination.ID == visitor.DestinationID {
+			return destination.URL, destination.ID
+		}
+	}
+
+	destination := pickDestination(shorten.Destinations)
+	return destination.URL, destination.ID
 }
 
-func (s *shortenService) GetOriginalURL(ctx context.Context, code string) (string, error) {
-	shorten, err := s.repo.FindByCode(ctx, code)
+// passThrough appends the decoded path suffix to the path of target and
+// merges query into its query string. Parameters target already has win over
+// those in query, so a visitor cannot override them; the others are appended
+// in their original order, repeated keys included.
+func passThrough(target, suffix, query string) string {
+	if suffix == "" && query == "" {
+		return target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	if escaped := escapePathSuffix(suffix); escaped != "" {
+		u = u.JoinPath(escaped)
+	}
+
+	if query != "" {
+		existing := u.Query()
+		rawQuery := u.RawQuery
+		for _, param := range strings.Split(query, "&") {
+			rawKey, _, _ := strings.Cut(param, "=")
+			key, err := url.QueryUnescape(rawKey)
+			if param == "" || err != nil || existing.Has(key) {
+				continue
+			}
+			if rawQuery != "" {
+				rawQuery += "&"
+			}
+			rawQuery += param
+		}
+		u.RawQuery = rawQuery
+	}
+	return u.String()
+}
+
+// escapePathSuffix cleans a decoded path on its own, so dot segments cannot
+// climb above the path it is appended to, and escapes its segments again for
+// JoinPath, which would drop it over a stray %. A trailing slash is kept.
+func escapePathSuffix(suffix string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+suffix), "/")
+	if cleaned == "" {
+		return ""
+	}
+
+	segments := strings.Split(cleaned, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	escaped := strings.Join(segments, "/")
+	if strings.HasSuffix(suffix, "/") {
+		escaped += "/"
+	}
+	return escaped
+}
+
+// addUTM adds the UTM parameters of shorten and of its campaign template to
+// target. A template that no longer exists adds nothing.
+func (s *shortenService) addUTM(ctx context.Context, shorten *models.Shorten, target string) (string, error) {
+	utm := shorten.UTM
+	if shorten.Campaign != "" {
+		workspace, err := s.workspaces.FindById(ctx, shorten.WorkspaceID)
+		if err != nil {
+			return "", err
+		}
+		if workspace != nil {
+			utm = utm.Merge(workspace.Settings.Campaigns[shorten.Campaign])
+		}
+	}
+	return utm.AddTo(target), nil
+}
+
+// checkCampaign rejects campaign names that are not a template of workspace
+func checkCampaign(workspace *models.Workspace, campaign string) error {
+	if campaign == "" {
+		return nil
+	}
+	if _, ok := workspace.Settings.Campaigns[campaign]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCampaign, campaign)
+	}
+	return nil
+}
+
+// pickDestination picks one of destinations at random, in proportion to
+// their weights
+func pickDestination(destinations []models.Destination) *models.Destination {
+	total := 0
+	for _, destination := range destinations {
+		total += destination.Weight
+	}
+	if total <= 0 {
+		return &destinations[0]
+	}
+
+	n := rand.IntN(total)
+	for i := range destinations {
+		n -= destinations[i].Weight
+		if n < 0 {
+			return &destinations[i]
+		}
+	}
+	return &destinations[len(destinations)-1]
+}
+
+// matchRule returns the URL of the first rule of shorten that matches
+// visitor, or "" if none does
+func (s *shortenService) matchRule(shorten *models.Shorten, visitor models.Visitor) string {
+	if len(shorten.Rules) == 0 {
+		return ""
+	}
+
+	device := utils.DeviceFromUserAgent(visitor.UserAgent)
+
+	// Only look the country up when a rule asks for it
+	var country string
+	for _, rule := range shorten.Rules {
+		if rule.Country != "" {
+			country = s.geo.Country(visitor.IP)
+			break
+		}
+	}
+
+	for _, rule := range shorten.Rules {
+		if rule.Matches(device, country) {
+			return rule.URL
+		}
+	}
+	return ""
+}
+
+// checkPassword verifies the password offered by visitor for a protected
+// link, locking the visitor out after redirect.passwordMaxAttempts failures
+func (s *shortenService) checkPassword(shorten *models.Shorten, visitor models.Visitor) error {
+	if visitor.Password == "" {
+		return ErrPasswordRequired
+	}
+
+	key := fmt.Sprintf("%d|%s", shorten.ID, visitor.IP)
+	now := time.Now()
+	if retryAfter := s.passwordAttempts.LockedFor(key, now); retryAfter > 0 {
+		return &PasswordLockedError{RetryAfter: retryAfter}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(shorten.PasswordHash), []byte(visitor.Password)); err != nil {
+		config := s.configService.GetConfig().Redirect
+		if config.PasswordMaxAttempts > 0 {
+			lockout := time.Duration(config.PasswordLockout) * time.Minute
+			if retryAfter := s.passwordAttempts.Fail(key, config.PasswordMaxAttempts, lockout, now); retryAfter > 0 {
+				return &PasswordLockedError{RetryAfter: retryAfter}
+			}
+		}
+		return ErrInvalidPassword
+	}
+
+	s.passwordAttempts.Reset(key)
+	return nil
+}
+
+// redirectRules turns the rules of a request into the rules of a link
+func redirectRules(requests []models.RedirectRuleRequest) []models.RedirectRule {
+	rules := make([]models.RedirectRule, len(requests))
+	for i, req := range requests {
+		rules[i] = models.RedirectRule{
+			Position: i,
+			Device:   req.Device,
+			Country:  req.Country,
+			URL:      req.URL,
+		}
+	}
+	return rules
+}
+
+// linkTags turns tag names into the tags of a link, trimmed and without
+// duplicates. The repository resolves them to the workspace's tags.
+func linkTags(names []string) []models.Tag {
+	tags := []models.Tag{}
+	for _, name := range tagNames(names) {
+		tags = append(tags, models.Tag{Name: name})
+	}
+	return tags
+}
+
+// tagNames trims names and drops empty and repeated ones
+func tagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// splitDestinations turns the destinations of a request into the
+// destinations of a link. Destinations whose URL is unchanged keep their ID,
+// so their clicks and visitors' sticky assignments carry over.
+func splitDestinations(current []models.Destination, requests []models.DestinationRequest) []models.Destination {
+	currentIDs := make(map[string]uint64, len(current))
+	for _, destination := range current {
+		currentIDs[destination.URL] = destination.ID
+	}
+
+	destinations := make([]models.Destination, len(requests))
+	for i, req := range requests {
+		id := currentIDs[req.URL]
+		// A URL listed twice must not reuse the ID twice
+		delete(currentIDs, req.URL)
+
+		destinations[i] = models.Destination{
+			ID:       id,
+			Position: i,
+			URL:      req.URL,
+			Weight:   req.Weight,
+		}
+	}
+	return destinations
+}
+
+// validateSchedule rejects links that would expire before they activate
+func validateSchedule(activatesAt, expiresAt time.Time) error {
+	if !activatesAt.IsZero() && !expiresAt.IsZero() && !expiresAt.After(activatesAt) {
+		return fmt.Errorf("%w: expiresAt must be after activatesAt", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// maxClicks returns the click limit requested for a new link, zero for none
+func maxClicks(req models.ShortenRequest) uint64 {
+	if req.MaxClicks == nil {
+		return 0
+	}
+	return *req.MaxClicks
+}
+
+// hashLinkPassword returns the stored form of a link password
+func hashLinkPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return "", err
 	}
+	return string(hash), nil
+}
 
-	if shorten == nil {
-		return "", errors.New("short URL not found")
+// unavailable wraps reason with where visitors of the unavailable link go
+// instead: its fallback URL, else its workspace's, else redirect.fallbackUrl
+func (s *shortenService) unavailable(ctx context.Context, shorten *models.Shorten, reason error) error {
+	config := s.configService.GetConfig().Redirect
+	unavailable := &UnavailableError{
+		Reason:       reason,
+		FallbackURL:  shorten.FallbackURL,
+		FallbackPage: config.FallbackPage,
+	}
+
+	if unavailable.FallbackURL == "" {
+		workspace, err := s.workspaces.FindById(ctx, shorten.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if workspace != nil {
+			unavailable.FallbackURL = workspace.Settings.FallbackURL
+		}
+	}
+
+	if unavailable.FallbackURL == "" {
+		unavailable.FallbackURL = config.FallbackURL
 	}
+	return unavailable
+}
 
-	// Check if expired
-	if !shorten.ExpiresAt.IsZero() && shorten.ExpiresAt.Before(time.Now()) {
-		return "", errors.New("shortened URL has expired")
+// redirectStatus picks the status of a link's redirect: its own, else its
+// workspace's default, else redirect.defaultStatus, else 302
+func (s *shortenService) redirectStatus(ctx context.Context, shorten *models.Shorten) (int, error) {
+	if shorten.RedirectStatus != 0 {
+		return shorten.RedirectStatus, nil
 	}
 
-	// Update click count asynchronously
-	go s.repo.IncrementClickCount(ctx, code)
+	workspace, err := s.workspaces.FindById(ctx, shorten.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+	if workspace != nil && workspace.Settings.DefaultRedirectStatus != 0 {
+		return workspace.Settings.DefaultRedirectStatus, nil
+	}
 
-	return shorten.OriginalURL, nil
+	if status := s.configService.GetConfig().Redirect.DefaultStatus; status != 0 {
+		return status, nil
+	}
+	return http.StatusFound, nil
 }
 
 func (s *shortenService) Create(ctx context.Context, req models.ShortenRequest) (*models.ShortenData, error) {
+	log.Debug().Str("customCode", req.CustomCode).Msg("Code passed")
+
+	principal, workspace, err := s.creationWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shorten, err := s.newShorten(ctx, principal, workspace, req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	newShorten, err := s.createLink(ctx, shorten)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toData(newShorten), nil
+}
+
+func (s *shortenService) CreateBatch(ctx context.Context, reqs []models.ShortenRequest, atomic bool) ([]BatchResult, error) {
+	principal, workspace, err := s.creationWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Codes taken by earlier items, which are not in the database yet when
+	// the batch is atomic
+	batchCodes := make(map[string]bool, len(reqs))
+
+	results := make([]BatchResult, len(reqs))
+	shortens := make([]*models.Shorten, len(reqs))
+	failed := false
+	for i, req := range reqs {
+		shorten, err := s.newShorten(ctx, principal, workspace, req, batchCodes)
+		if err != nil {
+			results[i].Err = err
+			failed = true
+			continue
+		}
+		batchCodes[shorten.ShortCode] = true
+
+		if atomic {
+			shortens[i] = shorten
+			continue
+		}
+
+		created, err := s.createLink(ctx, shorten)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Data = s.toData(created)
+	}
+
+	if !atomic {
+		return results, nil
+	}
+
+	// All or nothing: one invalid item fails the whole batch
+	if failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrBatchAborted
+			}
+		}
+		return results, nil
+	}
+
+	if err := s.repo.CreateAll(ctx, shortens); err != nil {
+		if errors.Is(err, repository.ErrDuplicateShortCode) {
+			err = ErrShortCodeExists
+		}
+		for i := range results {
+			results[i].Err = err
+		}
+		return results, nil
+	}
+
+	for i, shorten := range shortens {
+		results[i].Data = s.toData(shorten)
+	}
+	return results, nil
+}
+
+// createLink stores shorten, reporting a code another request took since it
+// was checked as ErrShortCodeExists
+func (s *shortenService) createLink(ctx context.Context, shorten *models.Shorten) (*models.Shorten, error) {
+	created, err := s.repo.Create(ctx, shorten)
+	if errors.Is(err, repository.ErrDuplicateShortCode) {
+		return nil, ErrShortCodeExists
+	}
+	return created, err
+}
+
+// Import creates a link for each record of an export, keeping its code,
+// creation date and click count. Records whose code is taken fail with
+// ErrShortCodeExists.
+func (s *shortenService) Import(ctx context.Context, records []models.ImportRecord) ([]BatchResult, error) {
+	principal, workspace, err := s.creationWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(records))
+	for i, record := range records {
+		req := models.ShortenRequest{OriginalURL: record.URL, CustomCode: record.Code}
+		shorten, err := s.newShorten(ctx, principal, workspace, req, nil)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		// Imported links keep working as they did before the move, so the
+		// workspace's default expiry does not apply to them
+		shorten.ExpiresAt = time.Time{}
+		shorten.ClickCount = record.Clicks
+		if !record.CreatedAt.IsZero() {
+			shorten.CreatedAt = record.CreatedAt
+		}
+
+		created, err := s.createLink(ctx, shorten)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Data = s.toData(created)
+	}
+	return results, nil
+}
+
+// creationWorkspace returns the caller and the workspace new links go into
+func (s *shortenService) creationWorkspace(ctx context.Context) (*models.Principal, *models.Workspace, error) {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal == nil || principal.WorkspaceID == 0 {
+		return nil, nil, ErrNoWorkspace
+	}
+
+	workspace, err := s.workspaces.FindById(ctx, principal.WorkspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if workspace == nil {
+		return nil, nil, ErrNoWorkspace
+	}
+	return principal, workspace, nil
+}
+
+// newShorten validates req and builds the link it creates in workspace,
+// without storing it. Codes in batchCodes count as taken.
+func (s *shortenService) newShorten(ctx context.Context, principal *models.Principal, workspace *models.Workspace, req models.ShortenRequest, batchCodes map[string]bool) (*models.Shorten, error) {
 	var shortCode string
 	var err error
 
-	log.Debug().Str("customCode", req.CustomCode).Msg("Code passed")
+	// Codes only need to be unique per domain: redirects on the default
+	// domain cannot tell workspaces apart, so they share its codes
+	var domain string
+	if workspace.Domain != nil {
+		domain = *workspace.Domain
+	}
 
 	if req.CustomCode != "" {
 		shortCode = req.CustomCode
+		if !shortCodePattern.MatchString(shortCode) {
+			return nil, fmt.Errorf("%w: use up to 64 letters, digits, dashes and underscores", ErrInvalidShortCode)
+		}
+		// Custom codes share the root path with the API, docs and health routes
+		if utils.IsReservedShortCode(shortCode) {
+			return nil, ErrShortCodeReserved
+		}
 		// Check if code already exists
-		existing, _ := s.repo.FindByCode(ctx, shortCode)
+		if batchCodes[shortCode] {
+			return nil, ErrShortCodeExists
+		}
+		existing, _ := s.repo.FindByCode(ctx, domain, shortCode)
 		if existing != nil {
-			return nil, errors.New("custom code already in use")
+			return nil, ErrShortCodeExists
 		}
 	} else {
 		// Generate random code
-		shortCode = utils.GenerateShortCode()
+		shortCode, err = s.generateUniqueCode(ctx, domain, batchCodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Set expiration time if specified, falling back to the workspace default
+	expiresAfter := req.ExpiresAfter
+	if expiresAfter == 0 {
+		expiresAfter = workspace.Settings.DefaultExpiresAfter
 	}
 
-	// Set expiration time if specified
 	var expiresAt time.Time
-	if req.ExpiresAfter > 0 {
-		expiresAt = time.Now().AddDate(0, 0, req.ExpiresAfter)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	} else if expiresAfter > 0 {
+		expiresAt = time.Now().AddDate(0, 0, expiresAfter)
+	}
+
+	var activatesAt time.Time
+	if req.ActivatesAt != nil {
+		activatesAt = *req.ActivatesAt
+	}
+
+	if err := validateSchedule(activatesAt, expiresAt); err != nil {
+		return nil, err
+	}
+
+	var utm models.UTMParams
+	if req.UTM != nil {
+		utm = *req.UTM
+	}
+
+	var campaign string
+	if req.Campaign != nil {
+		campaign = *req.Campaign
+	}
+	if err := checkCampaign(workspace, campaign); err != nil {
+		return nil, err
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		passwordHash, err = hashLinkPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	shorten := &models.Shorten{
@@ -100,72 +885,248 @@ func (s *shortenService) Create(ctx context.Context, req models.ShortenRequest)
 		UpdatedAt:   time.Now(),
 		ClickCount:  0,
 		ExpiresAt:   expiresAt,
-	}
+		ActivatesAt: activatesAt,
+		MaxClicks:   maxClicks(req),
+		Disabled:    req.Disabled != nil && *req.Disabled,
+		FallbackURL: req.FallbackURL,
+		Rules:       redirectRules(req.Rules),
 
-	newShorten, err := s.repo.Create(ctx, shorten)
-	if err != nil {
-		return nil, err
+		Destinations: splitDestinations(nil, req.Destinations),
+
+		UTM:         utm,
+		Campaign:    campaign,
+		Passthrough: req.Passthrough != nil && *req.Passthrough,
+		Tags:        linkTags(req.Tags),
+
+		RedirectStatus: req.RedirectStatus,
+		PasswordHash:   passwordHash,
+
+		WorkspaceID:   workspace.ID,
+		Domain:        domain,
+		OwnerUserID:   principal.UserID,
+		OwnerAPIKeyID: principal.APIKeyID,
 	}
 
-	return &models.ShortenData{
-		Shorten:  newShorten,
-		ShortURL: fmt.Sprintf("%s/%s", s.baseURL, shortCode),
-	}, nil
+	return shorten, nil
+}
+
+// generateUniqueCode returns a random code that is neither reserved, in
+// batchCodes nor taken on domain
+func (s *shortenService) generateUniqueCode(ctx context.Context, domain string, batchCodes map[string]bool) (string, error) {
+	for i := 0; i < maxCodeAttempts; i++ {
+		code := utils.GenerateShortCode()
+		if utils.IsReservedShortCode(code) || batchCodes[code] {
+			continue
+		}
+
+		existing, err := s.repo.FindByCode(ctx, domain, code)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate a unique short code")
 }
 
 func (s *shortenService) Update(ctx context.Context, code string, req models.ShortenRequest) (*models.ShortenData, error) {
-	shorten, err := s.repo.FindByCode(ctx, code)
+	// Links of other owners are reported as missing rather than forbidden
+	shorten, err := s.repo.FindScopedByCode(ctx, code, linkScope(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	if shorten == nil {
-		return nil, errors.New("short URL not found")
+		return nil, ErrShortURLNotFound
 	}
 
 	shorten.OriginalURL = req.OriginalURL
 	shorten.UpdatedAt = time.Now()
 
-	// Update expiration if provided
-	if req.ExpiresAfter > 0 {
+	// Update expiration and activation if provided
+	if req.ExpiresAt != nil {
+		shorten.ExpiresAt = *req.ExpiresAt
+	} else if req.ExpiresAfter > 0 {
 		shorten.ExpiresAt = time.Now().AddDate(0, 0, req.ExpiresAfter)
 	}
 
+	if req.ActivatesAt != nil {
+		shorten.ActivatesAt = *req.ActivatesAt
+	}
+
+	if err := validateSchedule(shorten.ActivatesAt, shorten.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if req.RedirectStatus != 0 {
+		shorten.RedirectStatus = req.RedirectStatus
+	}
+
+	if req.MaxClicks != nil {
+		shorten.MaxClicks = *req.MaxClicks
+	}
+
+	if req.Disabled != nil {
+		shorten.Disabled = *req.Disabled
+	}
+
+	if req.Rules != nil {
+		shorten.Rules = redirectRules(req.Rules)
+	}
+
+	if req.Destinations != nil {
+		shorten.Destinations = splitDestinations(shorten.Destinations, req.Destinations)
+	}
+
+	if req.UTM != nil {
+		shorten.UTM = *req.UTM
+	}
+
+	if req.Passthrough != nil {
+		shorten.Passthrough = *req.Passthrough
+	}
+	if req.Tags != nil {
+		shorten.Tags = linkTags(req.Tags)
+	}
+
+	if req.Campaign != nil && *req.Campaign != shorten.Campaign {
+		workspace, err := s.workspaces.FindById(ctx, shorten.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if workspace == nil {
+			return nil, ErrNoWorkspace
+		}
+		if err := checkCampaign(workspace, *req.Campaign); err != nil {
+			return nil, err
+		}
+		shorten.Campaign = *req.Campaign
+	}
+
+	if req.RemoveFallbackURL {
+		shorten.FallbackURL = ""
+	} else if req.FallbackURL != "" {
+		shorten.FallbackURL = req.FallbackURL
+	}
+
+	if req.RemovePassword {
+		shorten.PasswordHash = ""
+	} else if req.Password != "" {
+		shorten.PasswordHash, err = hashLinkPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updatedShorten, err := s.repo.Update(ctx, shorten)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.ShortenData{
-		Shorten:  updatedShorten,
-		ShortURL: fmt.Sprintf("%s/%s", s.baseURL, code),
-	}, nil
+	return s.toData(updatedShorten), nil
 }
 
 func (s *shortenService) Delete(ctx context.Context, code string) error {
 	log := utils.LoggerFromContext(ctx)
-	shorten, err := s.repo.FindByCode(ctx, code)
+
+	deleted, err := s.repo.Delete(ctx, code, linkScope(ctx))
 	if err != nil {
+		log.Error().Err(err).Str("code", code).Msg("Error deleting")
 		return err
 	}
 
-	if shorten == nil {
-		return errors.New("short URL not found")
+	if !deleted {
+		return ErrShortURLNotFound
 	}
+	return nil
+}
 
-	id, err := s.repo.Delete(ctx, code)
+// Bulk applies req.Action to every link of the caller that req selects, or
+// only reports them on a dry run
+func (s *shortenService) Bulk(ctx context.Context, req models.BulkLinkRequest) (*models.BulkLinkResult, error) {
+	log := utils.LoggerFromContext(ctx)
+
+	selection, err := bulkSelection(ctx, req)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Error deleting")
-		return err
+		return nil, err
 	}
-	return nil
+
+	shortens, err := s.repo.Select(ctx, selection)
+	if err != nil {
+		log.Error().Err(err).Str("action", string(req.Action)).Msg("Error selecting links")
+		return nil, err
+	}
+
+	result := &models.BulkLinkResult{
+		Action:  req.Action,
+		DryRun:  req.DryRun,
+		Matched: len(shortens),
+		Codes:   make([]string, len(shortens)),
+	}
+	ids := make([]uint64, len(shortens))
+	for i, shorten := range shortens {
+		ids[i] = shorten.ID
+		result.Codes[i] = shorten.ShortCode
+	}
+
+	if req.DryRun || len(ids) == 0 {
+		return result, nil
+	}
+
+	switch req.Action {
+	case models.BulkActionDelete:
+		err = s.repo.DeleteAll(ctx, ids)
+	case models.BulkActionExpire:
+		err = s.repo.ExpireAll(ctx, ids, time.Now())
+	case models.BulkActionRetag:
+		err = s.repo.Retag(ctx, selection.Scope.WorkspaceID, ids, tagNames(req.AddTags), tagNames(req.RemoveTags))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("action", string(req.Action)).Int("links", len(ids)).Msg("Error applying bulk operation")
+		return nil, err
+	}
+
+	log.Info().Str("action", string(req.Action)).Int("links", len(ids)).Msg("Applied bulk operation")
+	return result, nil
+}
+
+// bulkSelection turns the codes and filter of req into a selection within
+// the caller's links. A request that would select every link is rejected.
+func bulkSelection(ctx context.Context, req models.BulkLinkRequest) (models.LinkSelection, error) {
+	selection := models.LinkSelection{
+		Scope:     linkScope(ctx),
+		Codes:     req.Codes,
+		Unexpired: req.Action == models.BulkActionExpire,
+	}
+
+	if req.Action == models.BulkActionRetag && len(tagNames(req.AddTags)) == 0 && len(tagNames(req.RemoveTags)) == 0 {
+		return selection, fmt.Errorf("%w: retag needs addTags or removeTags", ErrInvalidBulkRequest)
+	}
+
+	if filter := req.Filter; filter != nil {
+		selection.Tag = strings.TrimSpace(filter.Tag)
+		selection.Domain = filter.Domain
+		if filter.CreatedBefore != "" {
+			createdBefore, err := utils.ParseTimeParam(filter.CreatedBefore)
+			if err != nil {
+				return selection, fmt.Errorf("%w: createdBefore: %v", ErrInvalidBulkRequest, err)
+			}
+			selection.CreatedBefore = &createdBefore
+		}
+	}
+
+	if len(selection.Codes) == 0 && selection.Tag == "" && selection.Domain == "" && selection.CreatedBefore == nil {
+		return selection, fmt.Errorf("%w: select links by codes or filter", ErrInvalidBulkRequest)
+	}
+	return selection, nil
 }
 
 func (s *shortenService) ShortCodeExists(ctx context.Context, code string) (bool, error) {
 	log := utils.LoggerFromContext(ctx)
 	log.Debug().Str("code", code).Msg("Checking if short code exists")
 
-	_, err := s.repo.FindByCode(ctx, code)
+	_, err := s.repo.FindScopedByCode(ctx, code, readScope(ctx))
 	if err != nil {
 		log.Error().Err(err).Str("code", code).Msg("Error finding short code")
 		return false, nil
@@ -175,7 +1136,7 @@ func (s *shortenService) ShortCodeExists(ctx context.Context, code string) (bool
 }
 
 func (s *shortenService) GetByOriginalUrl(ctx context.Context, url string) (*models.ShortenData, bool, error) {
-	shorten, err := s.repo.FindByOriginalURL(ctx, url)
+	shorten, err := s.repo.FindByOriginalURL(ctx, url, linkScope(ctx))
 	if err != nil {
 		return nil, false, err
 	}
@@ -184,8 +1145,76 @@ func (s *shortenService) GetByOriginalUrl(ctx context.Context, url string) (*mod
 		return nil, false, nil
 	}
 
-	return &models.ShortenData{
-		Shorten:  shorten,
-		ShortURL: fmt.Sprintf("%s/%s", s.baseURL, shorten.ShortCode),
-	}, true, nil
+	return s.toData(shorten), true, nil
+}
+
+func (s *shortenService) List(ctx context.Context, query models.ShortenListQuery) (*models.PaginatedData[models.ShortenData], error) {
+	maxPageSize := s.configService.GetConfig().App.MaxPageSize
+
+	pageSize := query.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if maxPageSize > 0 && pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	page := query.Page
+	if page == 0 {
+		page = 1
+	}
+
+	filter, err := linkFilter(ctx, query.LinkFilterQuery)
+	if err != nil {
+		return nil, err
+	}
+	filter.SortBy = query.SortBy
+	filter.Descending = query.Order != "asc"
+	filter.Offset = (page - 1) * pageSize
+	filter.Limit = pageSize
+
+	shortens, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.ShortenData, len(shortens))
+	for i := range shortens {
+		items[i] = *s.toData(&shortens[i])
+	}
+
+	return &models.PaginatedData[models.ShortenData]{
+		Items:      items,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// linkFilter turns query into a filter over the links of the caller's
+// workspace
+func linkFilter(ctx context.Context, query models.LinkFilterQuery) (models.ShortenFilter, error) {
+	filter := models.ShortenFilter{
+		Scope:   readScope(ctx),
+		Search:  query.Search,
+		Expired: query.Expired,
+		Tag:     strings.TrimSpace(query.Tag),
+	}
+
+	if query.CreatedFrom != "" {
+		createdFrom, err := utils.ParseTimeParam(query.CreatedFrom)
+		if err != nil {
+			return filter, fmt.Errorf("%w: createdFrom: %v", ErrInvalidListQuery, err)
+		}
+		filter.CreatedFrom = &createdFrom
+	}
+	if query.CreatedTo != "" {
+		createdTo, err := utils.ParseTimeParam(query.CreatedTo)
+		if err != nil {
+			return filter, fmt.Errorf("%w: createdTo: %v", ErrInvalidListQuery, err)
+		}
+		filter.CreatedTo = &createdTo
+	}
+	return filter, nil
 }
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+)
+
+// TagService reports on the tags of links
+type TagService interface {
+	Stats(ctx context.Context) ([]models.TagStats, error)
+}
+
+type tagService struct {
+	repo repository.TagRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(repo repository.TagRepository) TagService {
+	return &tagService{
+		repo: repo,
+	}
+}
+
+// Stats totals the links and clicks of each tag over the links of the
+// caller's workspace
+func (s *tagService) Stats(ctx context.Context) ([]models.TagStats, error) {
+	log := utils.LoggerFromContext(ctx)
+
+	stats, err := s.repo.Stats(ctx, readScope(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("Error totalling tags")
+		return nil, err
+	}
+	return stats, nil
+}
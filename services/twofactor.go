@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"portus/models"
+	"portus/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Errors returned by TwoFactorService
+var (
+	ErrTwoFactorRequired       = errors.New("two-factor code required")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorEnforced       = errors.New("two-factor authentication is required by the administrator")
+	ErrTwoFactorLocked         = errors.New("too many invalid two-factor codes")
+)
+
+// TwoFactorLockedError is returned while a user is locked out of two-factor
+// verification. It matches ErrTwoFactorLocked.
+type TwoFactorLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TwoFactorLockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTwoFactorLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *TwoFactorLockedError) Unwrap() error {
+	return ErrTwoFactorLocked
+}
+
+const (
+	// totpPeriod is the TOTP time step in seconds
+	totpPeriod = 30
+	// totpSkew is how many steps either side of now are accepted, to tolerate clock drift
+	totpSkew = 1
+	// recoveryCodeCount is how many recovery codes a user receives
+	recoveryCodeCount = 10
+	// recoveryCodeBytes gives 80 bits of randomness per recovery code
+	recoveryCodeBytes = 10
+	// qrCodeSize is the width and height of the enrollment QR code in pixels
+	qrCodeSize = 256
+	// twoFactorMaxAttempts is how many invalid codes lock a user out, so
+	// the 6-digit codes cannot be guessed
+	twoFactorMaxAttempts = 5
+	// twoFactorLockout is how long a locked out user must wait
+	twoFactorLockout = 15 * time.Minute
+)
+
+// TwoFactorService provides methods for TOTP enrollment and verification
+type TwoFactorService interface {
+	Setup(ctx context.Context, principal *models.Principal) (*models.TwoFactorSetupData, error)
+	Enable(ctx context.Context, principal *models.Principal, code string) (*models.RecoveryCodesData, error)
+	Disable(ctx context.Context, principal *models.Principal, req models.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, principal *models.Principal, code string) (*models.RecoveryCodesData, error)
+	Verify(ctx context.Context, user *models.User, code, recoveryCode string) error
+}
+
+type twoFactorService struct {
+	users         repository.UserRepository
+	recoveryCodes repository.RecoveryCodeRepository
+	configService ConfigService
+	attempts      *attemptLimiter
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(users repository.UserRepository, recoveryCodes repository.RecoveryCodeRepository, configService ConfigService) TwoFactorService {
+	return &twoFactorService{
+		users:         users,
+		recoveryCodes: recoveryCodes,
+		configService: configService,
+		attempts:      newAttemptLimiter(),
+	}
+}
+
+// Setup generates a new TOTP secret. It is not enforced until confirmed via Enable.
+func (s *twoFactorService) Setup(ctx context.Context, principal *models.Principal) (*models.TwoFactorSetupData, error) {
+	user, err := s.userFromPrincipal(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.configService.GetConfig().App.Name,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	qrCode, err := qrCodeDataURI(key)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = key.Secret()
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if _, err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupData{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCode:     qrCode,
+	}, nil
+}
+
+// Enable confirms enrollment with a first code and issues recovery codes
+func (s *twoFactorService) Enable(ctx context.Context, principal *models.Principal, code string) (*models.RecoveryCodesData, error) {
+	user, err := s.userFromPrincipal(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	user.UpdatedAt = time.Now()
+	if _, err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, principal *models.Principal, req models.TwoFactorDisableRequest) error {
+	if s.configService.GetConfig().Auth.Enable2FA {
+		return ErrTwoFactorEnforced
+	}
+
+	user, err := s.userFromPrincipal(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotSetUp
+	}
+
+	if err := s.Verify(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if _, err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.recoveryCodes.DeleteForUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes invalidates every previous recovery code
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, principal *models.Principal, code string) (*models.RecoveryCodesData, error) {
+	user, err := s.userFromPrincipal(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// Verify checks a TOTP code, or a recovery code when no TOTP code is given
+func (s *twoFactorService) Verify(ctx context.Context, user *models.User, code, recoveryCode string) error {
+	switch {
+	case code != "":
+		return s.verifyTOTP(ctx, user, code)
+	case recoveryCode != "":
+		return s.limit(user, func() error {
+			used, err := s.recoveryCodes.Use(ctx, user.ID, hashSecret(normalizeRecoveryCode(recoveryCode)), time.Now())
+			if err != nil {
+				return err
+			}
+			if !used {
+				return ErrInvalidTwoFactorCode
+			}
+			return nil
+		})
+	default:
+		return ErrTwoFactorRequired
+	}
+}
+
+// verifyTOTP checks a TOTP code, counting invalid ones against the user
+func (s *twoFactorService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	return s.limit(user, func() error {
+		return s.matchTOTP(ctx, user, code)
+	})
+}
+
+// limit runs check unless user is locked out, and locks the user out after
+// twoFactorMaxAttempts invalid codes in a row
+func (s *twoFactorService) limit(user *models.User, check func() error) error {
+	key := strconv.FormatUint(user.ID, 10)
+	now := time.Now()
+	if retryAfter := s.attempts.LockedFor(key, now); retryAfter > 0 {
+		return &TwoFactorLockedError{RetryAfter: retryAfter}
+	}
+
+	err := check()
+	switch {
+	case err == nil:
+		s.attempts.Reset(key)
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		if retryAfter := s.attempts.Fail(key, twoFactorMaxAttempts, twoFactorLockout, now); retryAfter > 0 {
+			return &TwoFactorLockedError{RetryAfter: retryAfter}
+		}
+	}
+	return err
+}
+
+// matchTOTP accepts a code from the current step or a neighbouring one, but
+// never a step at or before the last accepted one
+func (s *twoFactorService) matchTOTP(ctx context.Context, user *models.User, code string) error {
+	current := time.Now().Unix() / totpPeriod
+
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		valid, err := hotp.ValidateCustom(strings.TrimSpace(code), uint64(step), user.TOTPSecret, hotp.ValidateOpts{
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil || !valid {
+			continue
+		}
+
+		advanced, err := s.users.AdvanceTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			// Code was already used
+			return ErrInvalidTwoFactorCode
+		}
+
+		user.TOTPLastStep = step
+		return nil
+	}
+
+	return ErrInvalidTwoFactorCode
+}
+
+func (s *twoFactorService) issueRecoveryCodes(ctx context.Context, userID uint64) (*models.RecoveryCodesData, error) {
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]models.RecoveryCode, recoveryCodeCount)
+
+	for i := range codes {
+		randomBytes := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := base32.StdEncoding.EncodeToString(randomBytes)
+		codes[i] = fmt.Sprintf("%s-%s-%s-%s", raw[0:4], raw[4:8], raw[8:12], raw[12:16])
+		stored[i] = models.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  hashSecret(raw),
+			CreatedAt: time.Now(),
+		}
+	}
+
+	if err := s.recoveryCodes.Replace(ctx, userID, stored); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodesData{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) userFromPrincipal(ctx context.Context, principal *models.Principal) (*models.User, error) {
+	if principal == nil || principal.UserID == nil {
+		return nil, ErrNotUserSession
+	}
+
+	user, err := s.users.FindById(ctx, *principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// normalizeRecoveryCode strips the separators users may or may not type
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// qrCodeDataURI renders the otpauth URI of key as a PNG data URI
+func qrCodeDataURI(key *otp.Key) (string, error) {
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to render QR code: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Errors returned by UserService
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username or email already in use")
+	ErrAdminDenied   = errors.New("creating an admin requires the config:admin or workspaces:admin scope")
+)
+
+// UserService provides methods to manage local user accounts
+type UserService interface {
+	Create(ctx context.Context, req models.CreateUserRequest) (*models.User, error)
+	List(ctx context.Context) ([]models.User, error)
+	GetById(ctx context.Context, id uint64) (*models.User, error)
+}
+
+type userService struct {
+	repo       repository.UserRepository
+	workspaces repository.WorkspaceRepository
+}
+
+// NewUserService creates a new user service
+func NewUserService(repo repository.UserRepository, workspaces repository.WorkspaceRepository) UserService {
+	return &userService{
+		repo:       repo,
+		workspaces: workspaces,
+	}
+}
+
+// Create adds a local user. Admins are granted every scope, so only callers
+// holding config:admin or workspaces:admin, as admins do, may create one.
+func (s *userService) Create(ctx context.Context, req models.CreateUserRequest) (*models.User, error) {
+	if req.IsAdmin {
+		principal := utils.PrincipalFromContext(ctx)
+		if !principal.HasScope(models.ScopeConfigAdmin) && !principal.HasScope(models.ScopeWorkspacesAdmin) {
+			return nil, ErrAdminDenied
+		}
+	}
+
+	username := strings.TrimSpace(req.Username)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	existing, err := s.repo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		existing, err = s.repo.FindByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hash),
+		IsAdmin:      req.IsAdmin,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	user, err = s.repo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// New users join the workspace of whoever created them
+	if principal := utils.PrincipalFromContext(ctx); principal != nil && principal.WorkspaceID != 0 {
+		role := req.Role
+		if role == "" {
+			role = models.RoleEditor
+		}
+
+		err := s.workspaces.AddMember(ctx, &models.WorkspaceMember{
+			WorkspaceID: principal.WorkspaceID,
+			UserID:      user.ID,
+			Role:        role,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (s *userService) List(ctx context.Context) ([]models.User, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *userService) GetById(ctx context.Context, id uint64) (*models.User, error) {
+	user, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"portus/models"
+	"portus/repository"
+	"portus/utils"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errors returned by WorkspaceService
+var (
+	ErrWorkspaceNotFound    = errors.New("workspace not found")
+	ErrWorkspaceSlugTaken   = errors.New("workspace slug is already taken")
+	ErrWorkspaceDomainTaken = errors.New("workspace domain is already taken")
+	ErrDomainCodesTaken     = errors.New("short codes of the workspace are already used on that domain")
+	ErrInvalidWorkspace     = errors.New("invalid workspace")
+	ErrNotWorkspaceMember   = errors.New("not a member of this workspace")
+	ErrNoWorkspace          = errors.New("no workspace selected")
+	ErrLastWorkspaceOwner   = errors.New("a workspace must keep at least one owner")
+	ErrDomainChangeDenied   = errors.New("changing a workspace domain requires the workspaces:admin scope")
+)
+
+// workspaceSlugPattern keeps slugs usable in URLs and headers
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// WorkspaceService manages workspaces, their members and which workspace a
+// request operates on
+type WorkspaceService interface {
+	Create(ctx context.Context, req models.WorkspaceRequest) (*models.Workspace, error)
+	Update(ctx context.Context, id uint64, req models.WorkspaceRequest) (*models.Workspace, error)
+	Get(ctx context.Context, id uint64) (*models.Workspace, error)
+	List(ctx context.Context) ([]models.Workspace, error)
+	ListMembers(ctx context.Context, id uint64) ([]models.WorkspaceMemberData, error)
+	AddMember(ctx context.Context, id uint64, req models.AddWorkspaceMemberRequest) error
+	UpdateMember(ctx context.Context, id, userID uint64, req models.UpdateWorkspaceMemberRequest) error
+	RemoveMember(ctx context.Context, id, userID uint64) error
+	Resolve(ctx context.Context, principal *models.Principal, requested uint64) error
+	EnsureDefaultWorkspace(ctx context.Context) (*models.Workspace, error)
+}
+
+type workspaceService struct {
+	repo          repository.WorkspaceRepository
+	users         repository.UserRepository
+	configService ConfigService
+}
+
+// NewWorkspaceService creates a new workspace service
+func NewWorkspaceService(repo repository.WorkspaceRepository, users repository.UserRepository, configService ConfigService) WorkspaceService {
+	return &workspaceService{
+		repo:          repo,
+		users:         users,
+		configService: configService,
+	}
+}
+
+// Create adds a workspace. A user creating it becomes its owner.
+func (s *workspaceService) Create(ctx context.Context, req models.WorkspaceRequest) (*models.Workspace, error) {
+	workspace := &models.Workspace{
+		CreatedAt: time.Now(),
+	}
+	if err := s.apply(ctx, workspace, req); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.repo.Create(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal := utils.PrincipalFromContext(ctx); principal != nil && principal.UserID != nil {
+		err := s.repo.AddMember(ctx, &models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      *principal.UserID,
+			Role:        models.RoleOwner,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return workspace, nil
+}
+
+// Update replaces the settings of a workspace. Changing its domain moves its
+// links along, unless another workspace already uses one of their codes
+// there.
+func (s *workspaceService) Update(ctx context.Context, id uint64, req models.WorkspaceRequest) (*models.Workspace, error) {
+	log := utils.LoggerFromContext(ctx)
+
+	workspace, err := s.getManaged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := workspace.Domain
+	if err := s.apply(ctx, workspace, req); err != nil {
+		return nil, err
+	}
+
+	if domainOf(previous) == domainOf(workspace.Domain) {
+		return s.repo.Update(ctx, workspace)
+	}
+
+	clashes, err := s.repo.UpdateDomain(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(clashes) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDomainCodesTaken, strings.Join(clashes, ", "))
+	}
+
+	log.Info().Uint64("workspaceId", id).Str("domain", domainOf(workspace.Domain)).Msg("Moved workspace links to new domain")
+	return workspace, nil
+}
+
+// apply validates req and copies it onto workspace. Only holders of
+// workspaces:admin may set or change the domain, since it decides which
+// links a host serves.
+func (s *workspaceService) apply(ctx context.Context, workspace *models.Workspace, req models.WorkspaceRequest) error {
+	if !workspaceSlugPattern.MatchString(req.Slug) {
+		return fmt.Errorf("%w: slug may only contain lowercase letters, digits and dashes", ErrInvalidWorkspace)
+	}
+
+	existing, err := s.repo.FindBySlug(ctx, req.Slug)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != workspace.ID {
+		return ErrWorkspaceSlugTaken
+	}
+
+	var domain string
+	if req.Domain != "" {
+		domain = utils.NormalizeHost(req.Domain)
+	}
+	if domain != domainOf(workspace.Domain) && !utils.PrincipalFromContext(ctx).HasScope(models.ScopeWorkspacesAdmin) {
+		return ErrDomainChangeDenied
+	}
+
+	workspace.Domain = nil
+	if domain != "" {
+		if slices.Contains(s.reservedHosts(), domain) {
+			return fmt.Errorf("%w: %s is reserved for Portus itself", ErrInvalidWorkspace, domain)
+		}
+		existing, err := s.repo.FindByDomain(ctx, domain)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != workspace.ID {
+			return ErrWorkspaceDomainTaken
+		}
+		workspace.Domain = &domain
+	}
+
+	workspace.Name = req.Name
+	workspace.Slug = req.Slug
+	workspace.Settings = req.Settings
+	workspace.UpdatedAt = time.Now()
+	return nil
+}
+
+// domainOf returns a workspace domain, empty for the default domain
+func domainOf(domain *string) string {
+	if domain == nil {
+		return ""
+	}
+	return *domain
+}
+
+// reservedHosts are the hosts Portus itself serves on. A workspace claiming
+// one would take over the links of the default domain.
+func (s *workspaceService) reservedHosts() []string {
+	hosts := []string{"localhost"}
+	config := s.configService.GetConfig()
+	for _, raw := range []string{config.App.AppURL, config.App.APIBaseURL} {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			hosts = append(hosts, utils.NormalizeHost(u.Host))
+		}
+	}
+	return hosts
+}
+
+// Get returns a workspace the caller can access. Workspaces the caller may
+// not see are reported as missing.
+func (s *workspaceService) Get(ctx context.Context, id uint64) (*models.Workspace, error) {
+	workspace, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	allowed, err := s.canAccess(ctx, utils.PrincipalFromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	return workspace, nil
+}
+
+// List returns every workspace to holders of workspaces:admin and the
+// caller's own workspaces to everyone else
+func (s *workspaceService) List(ctx context.Context) ([]models.Workspace, error) {
+	principal := utils.PrincipalFromContext(ctx)
+	if principal.HasScope(models.ScopeWorkspacesAdmin) {
+		return s.repo.List(ctx)
+	}
+
+	if principal != nil && principal.UserID != nil {
+		return s.repo.ListForUser(ctx, *principal.UserID)
+	}
+
+	workspaces := []models.Workspace{}
+	if principal != nil {
+		workspace, err := s.repo.FindById(ctx, principal.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if workspace != nil {
+			workspaces = append(workspaces, *workspace)
+		}
+	}
+	return workspaces, nil
+}
+
+// getManaged returns a workspace the caller may administer: any workspace
+// for holders of workspaces:admin, the current one for holders of
+// members:admin. Other workspaces are reported as missing.
+func (s *workspaceService) getManaged(ctx context.Context, id uint64) (*models.Workspace, error) {
+	workspace, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := utils.PrincipalFromContext(ctx)
+	if principal.HasScope(models.ScopeWorkspacesAdmin) {
+		return workspace, nil
+	}
+	if principal.WorkspaceID == id && principal.HasScope(models.ScopeMembersAdmin) {
+		return workspace, nil
+	}
+	return nil, ErrWorkspaceNotFound
+}
+
+func (s *workspaceService) ListMembers(ctx context.Context, id uint64) ([]models.WorkspaceMemberData, error) {
+	if _, err := s.getManaged(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListMembers(ctx, id)
+}
+
+func (s *workspaceService) AddMember(ctx context.Context, id uint64, req models.AddWorkspaceMemberRequest) error {
+	if _, err := s.getManaged(ctx, id); err != nil {
+		return err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleEditor
+	}
+	if !role.IsValid() {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidWorkspace, role)
+	}
+
+	user, err := s.users.FindById(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	return s.repo.AddMember(ctx, &models.WorkspaceMember{
+		WorkspaceID: id,
+		UserID:      user.ID,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (s *workspaceService) UpdateMember(ctx context.Context, id, userID uint64, req models.UpdateWorkspaceMemberRequest) error {
+	if _, err := s.getManaged(ctx, id); err != nil {
+		return err
+	}
+
+	if !req.Role.IsValid() {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidWorkspace, req.Role)
+	}
+
+	if req.Role != models.RoleOwner {
+		if err := s.ensureOtherOwner(ctx, id, userID); err != nil {
+			return err
+		}
+	}
+
+	updated, err := s.repo.UpdateMemberRole(ctx, id, userID, req.Role)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *workspaceService) RemoveMember(ctx context.Context, id, userID uint64) error {
+	if _, err := s.getManaged(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.ensureOtherOwner(ctx, id, userID); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ensureOtherOwner fails when userID is the only owner of workspace id, so
+// demoting or removing them would leave it without one
+func (s *workspaceService) ensureOtherOwner(ctx context.Context, id, userID uint64) error {
+	member, err := s.repo.FindMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != models.RoleOwner {
+		return nil
+	}
+
+	owners, err := s.repo.CountOwners(ctx, id)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+// Resolve sets the workspace a request operates on and, for users, the role
+// and scopes they hold there. API keys are bound to their own workspace.
+// Users operate on requested, or on their oldest membership when requested
+// is zero; users without any membership are left without a workspace.
+func (s *workspaceService) Resolve(ctx context.Context, principal *models.Principal, requested uint64) error {
+	if principal.UserID == nil {
+		if requested != 0 && requested != principal.WorkspaceID {
+			return ErrNotWorkspaceMember
+		}
+		return nil
+	}
+
+	var member *models.WorkspaceMember
+	var err error
+	if requested == 0 {
+		member, err = s.repo.FirstMembership(ctx, *principal.UserID)
+	} else {
+		member, err = s.repo.FindMember(ctx, requested, *principal.UserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if member == nil {
+		if requested == 0 {
+			return nil
+		}
+
+		// Instance admins may operate on workspaces they do not belong to
+		if !principal.HasScope(models.ScopeWorkspacesAdmin) {
+			return ErrNotWorkspaceMember
+		}
+		workspace, err := s.repo.FindById(ctx, requested)
+		if err != nil {
+			return err
+		}
+		if workspace == nil {
+			return ErrNotWorkspaceMember
+		}
+		principal.WorkspaceID = requested
+		return nil
+	}
+
+	principal.WorkspaceID = member.WorkspaceID
+	principal.Role = member.Role
+
+	// Sessions restricted to 2FA enrollment gain nothing from their role
+	if !principal.TwoFactorSetupRequired {
+		principal.Scopes = mergeScopes(principal.Scopes, member.Role.Scopes())
+	}
+	return nil
+}
+
+// mergeScopes returns the union of a and b
+func mergeScopes(a, b []models.Scope) []models.Scope {
+	merged := append([]models.Scope{}, a...)
+	for _, scope := range b {
+		if !slices.Contains(merged, scope) {
+			merged = append(merged, scope)
+		}
+	}
+	return merged
+}
+
+// canAccess reports whether principal may operate on workspace id
+func (s *workspaceService) canAccess(ctx context.Context, principal *models.Principal, id uint64) (bool, error) {
+	if principal == nil {
+		return false, nil
+	}
+	if principal.HasScope(models.ScopeWorkspacesAdmin) {
+		return true, nil
+	}
+	if principal.UserID == nil {
+		return principal.WorkspaceID == id, nil
+	}
+	member, err := s.repo.FindMember(ctx, id, *principal.UserID)
+	return member != nil, err
+}
+
+// EnsureDefaultWorkspace creates the default workspace on first start. When
+// it does, links, API keys and users that predate workspaces move into it.
+func (s *workspaceService) EnsureDefaultWorkspace(ctx context.Context) (*models.Workspace, error) {
+	workspace, err := s.repo.FindBySlug(ctx, models.DefaultWorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace != nil {
+		return workspace, nil
+	}
+
+	workspace, err = s.repo.Create(ctx, &models.Workspace{
+		Name:      "Default",
+		Slug:      models.DefaultWorkspaceSlug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AdoptOrphans(ctx, workspace.ID); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"portus/models"
+	"strings"
+)
+
+// DeviceFromUserAgent classifies a User-Agent header. Anything that is
+// neither iOS nor Android, including bots, counts as desktop.
+func DeviceFromUserAgent(userAgent string) models.Device {
+	switch {
+	case strings.Contains(userAgent, "iPhone"),
+		strings.Contains(userAgent, "iPad"),
+		strings.Contains(userAgent, "iPod"):
+		return models.DeviceIOS
+	case strings.Contains(userAgent, "Android"):
+		return models.DeviceAndroid
+	default:
+		return models.DeviceDesktop
+	}
+}
@@ -11,16 +11,17 @@ import (
 
 // StatusCodeToErrorType maps HTTP status codes to ErrorType
 var StatusCodeToErrorType = map[int]models.ErrorType{
-	http.StatusBadRequest:          models.ErrorTypeBadRequest,
-	http.StatusUnauthorized:        models.ErrorTypeUnauthorized,
-	http.StatusForbidden:           models.ErrorTypeForbidden,
-	http.StatusNotFound:            models.ErrorTypeNotFound,
-	http.StatusConflict:            models.ErrorTypeConflict,
-	http.StatusUnprocessableEntity: models.ErrorTypeUnprocessableEntity,
-	http.StatusTooManyRequests:     models.ErrorTypeRateLimited,
-	http.StatusInternalServerError: models.ErrorTypeInternalError,
-	http.StatusServiceUnavailable:  models.ErrorTypeServiceUnavailable,
-	http.StatusGatewayTimeout:      models.ErrorTypeTimeout,
+	http.StatusBadRequest:            models.ErrorTypeBadRequest,
+	http.StatusUnauthorized:          models.ErrorTypeUnauthorized,
+	http.StatusForbidden:             models.ErrorTypeForbidden,
+	http.StatusNotFound:              models.ErrorTypeNotFound,
+	http.StatusConflict:              models.ErrorTypeConflict,
+	http.StatusRequestEntityTooLarge: models.ErrorTypeBadRequest,
+	http.StatusUnprocessableEntity:   models.ErrorTypeUnprocessableEntity,
+	http.StatusTooManyRequests:       models.ErrorTypeRateLimited,
+	http.StatusInternalServerError:   models.ErrorTypeInternalError,
+	http.StatusServiceUnavailable:    models.ErrorTypeServiceUnavailable,
+	http.StatusGatewayTimeout:        models.ErrorTypeTimeout,
 }
 
 // DefaultErrorMessages maps ErrorType to default human-readable messages
@@ -107,6 +108,10 @@ func RespondValidationError(c *gin.Context, err error, customMessage ...string)
 	RespondWithError(c, http.StatusBadRequest, err, message)
 }
 
+func RespondTooManyRequests(c *gin.Context, err error, customMessage ...string) {
+	RespondWithError(c, http.StatusTooManyRequests, err, customMessage...)
+}
+
 func RespondInternalError(c *gin.Context, err error, customMessage ...string) {
 	RespondWithError(c, http.StatusInternalServerError, err, customMessage...)
 }
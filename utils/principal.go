@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"portus/models"
+)
+
+type principalCtxKey struct{}
+
+var principalKey = principalCtxKey{}
+
+// WithPrincipal adds the authenticated caller to context
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext extracts the authenticated caller, or nil for public routes
+func PrincipalFromContext(ctx context.Context) *models.Principal {
+	if ctx == nil {
+		return nil
+	}
+	if principal, ok := ctx.Value(principalKey).(*models.Principal); ok {
+		return principal
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"net"
+	"portus/constants"
+	"strings"
 	"time"
 )
 
@@ -39,3 +42,21 @@ func GenerateShortCode() string {
 
 	return string(shortCode)
 }
+
+// IsReservedShortCode reports whether code collides with a path Portus serves itself
+func IsReservedShortCode(code string) bool {
+	for _, reserved := range constants.ReservedShortCodes {
+		if strings.EqualFold(code, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeHost strips the port from a Host header and lowercases it
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseTimeParam parses a query parameter given either as an RFC3339
+// timestamp or as a plain date (interpreted as midnight UTC)
+func ParseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339 or YYYY-MM-DD", value)
+}